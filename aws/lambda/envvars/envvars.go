@@ -3,13 +3,14 @@ package envvars
 import (
 	"encoding/base64"
 	"fmt"
-//	"log"	//	only used if lines marked DEBUG are uncommented
 	"os"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/kms"
+
+	"github.com/imtlab/pkg/loggers"
 )
 
 /*
@@ -107,15 +108,17 @@ func (p *tEnvVar) decrypt(key string, pKMS *kms.KMS, encryptionContext map[strin
 			EncryptionContext:	encryptionContext,
 		}
 
-//		log.Println(`Calling pKMS.Decrypt()`)											//<<<<	DEBUG
+		loggers.Debug.Println(`Calling pKMS.Decrypt()`)
 
 		//func (c *KMS) Decrypt(input *DecryptInput) (*DecryptOutput, error)
 		var pDecryptOutput *kms.DecryptOutput
 		if pDecryptOutput, err = pKMS.Decrypt(pDecryptInput); nil == err {
-//		log.Println(`Exited pKMS.Decrypt()`)											//<<<<	DEBUG
+			loggers.Debug.Println(`Exited pKMS.Decrypt()`)
 
 			//	Plaintext is a byte array, so convert to string
 			p.Plaintext = string(pDecryptOutput.Plaintext[:])
+			//	keep it out of the logs, however it might get there
+			loggers.RegisterSecret(p.Plaintext)
 		} else {
 			err = fmt.Errorf(`Failed to decrypt %s environment variable: %w`, key, err)
 		}
@@ -199,7 +202,7 @@ func (m TEnvVarMap) Validate() (err error) {
 
 				//	range over the map to decrypt the items requiring it
 				for key, pEnvVar := range m {
-//					log.Printf(`key = %s; Encrypted = %v`, key, pEnvVar.Encrypted)		//<<<<	DEBUG
+					loggers.Debug.Printf(`key = %s; Encrypted = %v`, key, pEnvVar.Encrypted)
 					if pEnvVar.Encrypted {
 						if err = pEnvVar.decrypt(key, pKMS, encryptionContext); nil != err {
 							break
@@ -2,6 +2,12 @@ module github.com/imtlab/pkg/aws/lambda
 
 go 1.26.0
 
-require github.com/aws/aws-sdk-go v1.44.234
+require (
+	github.com/aws/aws-sdk-go v1.44.234
+	github.com/imtlab/pkg/loggers v0.0.0-20240623000418-ea5c0eebbcfe
+)
 
 require github.com/jmespath/go-jmespath v0.4.0 // indirect
+
+// RegisterSecret() is newer than any published loggers, so build against the copy in this repository
+replace github.com/imtlab/pkg/loggers => ../../loggers
@@ -0,0 +1,95 @@
+package loggers
+
+import (
+	"io"
+	"log"
+	"log/slog"
+	"os"
+)
+
+//	A TFileName selects whether, and how, a level's lines name the source file that logged them.
+type TFileName int
+
+const (
+	FileNameNone	TFileName = iota
+	FileNameShort					//	"file.go:23: ", like log.Lshortfile
+	FileNameLong					//	"/full/path/to/file.go:23: ", like log.Llongfile
+)
+
+//	TLevelConfig configures one level of the classic format.
+type TLevelConfig struct {
+	Writer		io.Writer	//	nil discards the level's output
+	Prefix		string		//	e.g. "INFO: "
+	FileName	TFileName
+}
+
+/*	TConfig configures the classic plain-text format in more detail than Init() allows.
+	Start from DefaultConfig() and change what you need.
+*/
+type TConfig struct {
+	UTC				bool	//	timestamps in UTC rather than local time
+	Microseconds	bool	//	timestamps to the microsecond
+	NoTimestamp		bool	//	no timestamps at all, e.g. in AWS Lambda, where CloudWatch records its own
+	Trace			TLevelConfig
+	Debug			TLevelConfig
+	Info			TLevelConfig
+	Warning			TLevelConfig
+	Error			TLevelConfig
+}
+
+/*	DefaultConfig returns the configuration Init(os.Stdout, os.Stdout, os.Stderr) sets up, which is also
+	what the package starts out with outside AWS Lambda.  In Lambda, NoTimestamp is set.
+*/
+func DefaultConfig() *TConfig {
+	return &TConfig{
+		NoTimestamp:	0 != len(os.Getenv(kEnvLambdaFunctionName)),
+		Trace:		TLevelConfig{Writer: os.Stdout, Prefix: `TRACE: `, FileName: FileNameShort},
+		Debug:		TLevelConfig{Writer: os.Stdout, Prefix: `DEBUG: `, FileName: FileNameShort},
+		Info:		TLevelConfig{Writer: os.Stdout, Prefix: `INFO: `},
+		Warning:	TLevelConfig{Writer: os.Stdout, Prefix: `WARNING: `, FileName: FileNameShort},
+		Error:		TLevelConfig{Writer: os.Stderr, Prefix: `ERROR: `, FileName: FileNameShort},
+	}
+}
+
+/*	Configure sets up the classic plain-text format as pConfig says.
+	Like Init(), it may be called at any time, even while other goroutines are logging.
+*/
+func Configure(pConfig *TConfig) {
+	setHandler(configHandler(pConfig))
+}
+
+func configHandler(pConfig *TConfig) slog.Handler {
+	flags := log.LstdFlags	//	LstdFlags = Ldate | Ltime
+	if pConfig.NoTimestamp {
+		flags = 0
+	} else {
+		if pConfig.UTC {
+			flags |= log.LUTC
+		}
+		if pConfig.Microseconds {
+			flags |= log.Lmicroseconds
+		}
+	}
+
+	output := func(levelConfig TLevelConfig) (output tLegacyOutput) {
+		output.writer, output.prefix, output.flags = levelConfig.Writer, levelConfig.Prefix, flags
+		if nil == output.writer {
+			output.writer = io.Discard
+		}
+		switch levelConfig.FileName {
+		case FileNameShort:
+			output.flags |= log.Lshortfile
+		case FileNameLong:
+			output.flags |= log.Llongfile
+		}
+		return
+	}
+
+	return newLegacyHandler([kLevelCount]tLegacyOutput{
+		kIndexTrace:	output(pConfig.Trace),
+		kIndexDebug:	output(pConfig.Debug),
+		kIndexInfo:		output(pConfig.Info),
+		kIndexWarning:	output(pConfig.Warning),
+		kIndexError:	output(pConfig.Error),
+	})
+}
@@ -0,0 +1,28 @@
+package loggers
+
+import (
+	"context"
+	"log/slog"
+)
+
+type tContextKey struct{}
+
+/*	WithContext returns a copy of ctx carrying a logger enriched with attrs (key/value pairs or
+	slog.Attrs, as for slog.Logger.With), e.g. the AWS request ID in a Lambda handler or the trace ID
+	in an HTTP handler.  If ctx already carries a logger, the new one extends it.
+*/
+func WithContext(ctx context.Context, attrs ...any) context.Context {
+	return context.WithValue(ctx, tContextKey{}, FromContext(ctx).With(attrs...))
+}
+
+/*	FromContext returns the logger stored in ctx by WithContext(), or Slog() if there is none.
+	Either way it writes through the same handler as Info, Warning and Error.
+*/
+func FromContext(ctx context.Context) *slog.Logger {
+	if nil != ctx {
+		if pLogger, ok := ctx.Value(tContextKey{}).(*slog.Logger); ok {
+			return pLogger
+		}
+	}
+	return Slog()
+}
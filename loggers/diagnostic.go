@@ -0,0 +1,38 @@
+package loggers
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+/*	the environment variable that turns on diagnostic mode at startup, e.g. LOGGERS_DIAGNOSTICS=true
+	(any value strconv.ParseBool accepts)
+*/
+const kEnvDiagnostics = `LOGGERS_DIAGNOSTICS`
+
+var bDiagnostics atomic.Bool
+
+/*	SetDiagnostics turns diagnostic mode on or off.  In diagnostic mode, packages report their
+	startup steps through Diagnostic(); otherwise those calls are silent.
+*/
+func SetDiagnostics(b bool) {
+	ensureSetup()	//	so that LOGGERS_DIAGNOSTICS can't override b later
+	bDiagnostics.Store(b)
+}
+
+//	Diagnostics reports whether diagnostic mode is on.
+func Diagnostics() bool {
+	ensureSetup()	//	LOGGERS_DIAGNOSTICS is read there
+	return bDiagnostics.Load()
+}
+
+/*	Diagnostic logs its arguments, in the manner of fmt.Sprintln, at Info level regardless of the
+	minimum level, but only in diagnostic mode.
+*/
+func Diagnostic(v ...any) {
+	ensureSetup()
+	if bDiagnostics.Load() {
+		logDirect(LevelInfo, `DIAGNOSTIC: ` + strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+	}
+}
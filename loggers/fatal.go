@@ -0,0 +1,86 @@
+package loggers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+var (
+	//	called by Flush(), in the order registered
+	mutexFlushers	sync.Mutex
+	xFlushers		[]func() error
+)
+
+/*	RegisterFlusher adds f to the functions Flush() calls, for writers that buffer their output, e.g. the
+	Sync method of a *TRotatingFile or the Flush method of a *bufio.Writer passed to Init().
+*/
+func RegisterFlusher(f func() error) {
+	mutexFlushers.Lock()
+	defer mutexFlushers.Unlock()
+	xFlushers = append(xFlushers, f)
+}
+
+/*	Flush writes any pending "Suppressed N repeats" summaries (see SetSampling()), then calls every function
+	registered with RegisterFlusher(), and returns the first error any of them returned.
+*/
+func Flush() (err error) {
+	flushSampling()
+
+	mutexFlushers.Lock()
+	defer mutexFlushers.Unlock()
+
+	for _, f := range xFlushers {
+		if errFlush := f(); nil == err {
+			err = errFlush
+		}
+	}
+	return
+}
+
+//	Fatal logs its arguments, in the manner of fmt.Sprint, at Error level, flushes, and exits with status 1.
+func Fatal(v ...any) {
+	logFatal(fmt.Sprint(v...))
+	os.Exit(1)
+}
+
+//	Fatalf is Fatal in the manner of fmt.Sprintf.
+func Fatalf(format string, v ...any) {
+	logFatal(fmt.Sprintf(format, v...))
+	os.Exit(1)
+}
+
+/*	Panic logs its arguments, in the manner of fmt.Sprint, at Error level, flushes, and panics with the
+	message, redacted since the runtime prints it.
+*/
+func Panic(v ...any) {
+	msg := fmt.Sprint(v...)
+	logFatal(msg)
+	panic(Redact(msg))
+}
+
+//	Panicf is Panic in the manner of fmt.Sprintf.
+func Panicf(format string, v ...any) {
+	msg := fmt.Sprintf(format, v...)
+	logFatal(msg)
+	panic(Redact(msg))
+}
+
+/*	logFatal logs msg at Error level, attributed to the caller of Fatal() etc., then flushes.  The record is
+	redacted but goes straight to the current handler, since neither the minimum level nor sampling should
+	keep the last words of a process from being written.
+*/
+func logFatal(msg string) {
+	//	skip [runtime.Callers, this function, Fatal etc.]
+	var xPCs [1]uintptr
+	runtime.Callers(3, xPCs[:])
+
+	ensureSetup()
+	r := redactRecord(slog.NewRecord(time.Now(), LevelError, msg, xPCs[0]))
+	pCurrent.Load().handler.Handle(context.Background(), r)
+	Flush()
+}
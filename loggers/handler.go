@@ -0,0 +1,337 @@
+package loggers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//\\//	package-scope constants and variables
+
+//	A Format selects how log records are written.
+type Format int
+
+const (
+	FormatLegacy	Format = iota	//	"INFO: 2009/01/23 01:23:23 message key=value", as set up by Init()
+	FormatText						//	slog.TextHandler: time=... level=INFO msg=message key=value
+	FormatJSON						//	slog.JSONHandler: {"time":...,"level":"INFO","msg":"message","key":"value"}
+	FormatLambda					//	AWS Lambda's JSON log format: {"level":"INFO","message":"message","requestId":...}
+)
+
+//	indexes into per-level arrays
+const (
+	kIndexTrace	= iota
+	kIndexDebug
+	kIndexInfo
+	kIndexWarning
+	kIndexError
+	kLevelCount
+)
+
+var (
+	xLevelNames	= [kLevelCount]string{`TRACE`, `DEBUG`, `INFO`, `WARNING`, `ERROR`}
+	xLevels		= [kLevelCount]slog.Level{LevelTrace, LevelDebug, LevelInfo, LevelWarning, LevelError}
+
+	//	set by setHandler()
+	pCurrent	atomic.Pointer[tCurrent]
+
+	//	guards setup()
+	onceSetup	sync.Once
+
+	//	pSlog writes through whatever handler is current
+	pSlog		= slog.New(tRootHandler{})
+)
+
+//\\//	type definitions (and attached methods)
+
+//	tCurrent boxes the current handler for atomic replacement.
+type tCurrent struct {
+	handler	slog.Handler
+}
+
+/*	tRootHandler is the handler behind Slog() and the *log.Logger variables.  It filters by the minimum level
+	and sampling, and passes everything else, redacted, to the current handler, applying its own attributes and
+	groups first so that loggers derived with With() or WithGroup() follow the handler when Init() replaces it.
+*/
+type tRootHandler struct {
+	xOps	[]func(slog.Handler) slog.Handler
+}
+
+func (h tRootHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= GetLevel()
+}
+
+func (h tRootHandler) Handle(ctx context.Context, r slog.Record) error {
+	ensureSetup()
+	r = redactRecord(r)
+	if !sampled(r) {
+		return nil
+	}
+
+	current := pCurrent.Load().handler
+	for _, op := range h.xOps {
+		current = op(current)
+	}
+	return current.Handle(ctx, r)
+}
+
+func (h tRootHandler) WithAttrs(xAttrs []slog.Attr) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler {
+		//	redact now rather than in WithAttrs(), so that secrets registered since are masked too
+		return next.WithAttrs(redactAttrs(xAttrs))
+	})
+}
+
+func (h tRootHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler {
+		return next.WithGroup(name)
+	})
+}
+
+func (h tRootHandler) with(op func(slog.Handler) slog.Handler) slog.Handler {
+	xOps := make([]func(slog.Handler) slog.Handler, len(h.xOps), len(h.xOps) + 1)
+	copy(xOps, h.xOps)
+	return tRootHandler{xOps: append(xOps, op)}
+}
+
+/*	tLevelWriter is the io.Writer behind each *log.Logger variable.  Each line written to it becomes
+	a record at its level.
+*/
+type tLevelWriter struct {
+	level	slog.Level
+}
+
+func (w tLevelWriter) Write(xBytes []byte) (n int, err error) {
+	n = len(xBytes)
+
+	ctx := context.Background()
+	root := tRootHandler{}
+	if !root.Enabled(ctx, w.level) {
+		return
+	}
+
+	//	skip [runtime.Callers, this function, log.(*Logger).output, log.(*Logger).Print*]
+	var xPCs [1]uintptr
+	runtime.Callers(4, xPCs[:])
+
+	r := slog.NewRecord(time.Now(), w.level, string(bytes.TrimSuffix(xBytes, []byte{'\n'})), xPCs[0])
+	if pID := pRequestID.Load(); nil != pID {
+		r.AddAttrs(slog.String(KeyRequestID, *pID))
+	}
+	err = root.Handle(ctx, r)
+	return
+}
+
+//	tLegacyOutput is where and how one level is written in FormatLegacy.
+type tLegacyOutput struct {
+	writer	io.Writer
+	prefix	string
+	flags	int		//	the log.Ldate etc. flags
+}
+
+/*	tLegacyHandler writes records the way the *log.Logger variables always have, with any attributes
+	appended as key=value pairs.
+*/
+type tLegacyHandler struct {
+	pMutex		*sync.Mutex		//	shared by handlers derived with WithAttrs() and WithGroup()
+	xOutputs	[kLevelCount]tLegacyOutput
+	attrs		string			//	preformatted " key=value" pairs
+	group		string			//	"group." prefix for subsequent keys
+}
+
+func (h *tLegacyHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *tLegacyHandler) Handle(_ context.Context, r slog.Record) (err error) {
+	output := h.xOutputs[levelIndex(r.Level)]
+
+	xBuf := make([]byte, 0, 128)
+	if 0 == output.flags & log.Lmsgprefix {
+		xBuf = append(xBuf, output.prefix...)
+	}
+	xBuf = appendHeader(xBuf, r, output.flags)
+	if 0 != output.flags & log.Lmsgprefix {
+		xBuf = append(xBuf, output.prefix...)
+	}
+	xBuf = append(xBuf, r.Message...)
+	xBuf = append(xBuf, h.attrs...)
+	r.Attrs(func(attr slog.Attr) bool {
+		xBuf = appendAttr(xBuf, h.group, attr)
+		return true
+	})
+	xBuf = append(xBuf, '\n')
+
+	h.pMutex.Lock()
+	_, err = output.writer.Write(xBuf)
+	h.pMutex.Unlock()
+	return
+}
+
+func (h *tLegacyHandler) WithAttrs(xAttrs []slog.Attr) slog.Handler {
+	h2 := *h
+	var xBuf []byte
+	for _, attr := range xAttrs {
+		xBuf = appendAttr(xBuf, h.group, attr)
+	}
+	h2.attrs += string(xBuf)
+	return &h2
+}
+
+func (h *tLegacyHandler) WithGroup(name string) slog.Handler {
+	if 0 == len(name) {
+		return h
+	}
+	h2 := *h
+	h2.group += name + `.`
+	return &h2
+}
+
+//\\//	functions
+
+//	setHandler atomically installs h as the current handler.
+func setHandler(h slog.Handler) {
+	ensureSetup()
+	pCurrent.Store(&tCurrent{handler: h})
+}
+
+/*	SetHandler makes every logger write through h, for output none of the formats cover, and returns the
+	handler it replaces so that it can be put back later.  Records reach h already filtered by the minimum
+	level and redacted.
+*/
+func SetHandler(h slog.Handler) (previous slog.Handler) {
+	ensureSetup()
+	return pCurrent.Swap(&tCurrent{handler: h}).handler
+}
+
+//	ensureSetup runs setup() the first time the package is used.
+func ensureSetup() {
+	onceSetup.Do(setup)
+}
+
+//	logDirect writes a record straight to the current handler, regardless of the minimum level.
+func logDirect(level slog.Level, msg string) {
+	pCurrent.Load().handler.Handle(context.Background(), slog.NewRecord(time.Now(), level, msg, 0))
+}
+
+func newLegacyHandler(xOutputs [kLevelCount]tLegacyOutput) *tLegacyHandler {
+	return &tLegacyHandler{pMutex: new(sync.Mutex), xOutputs: xOutputs}
+}
+
+//	structuredOptions returns the slog.HandlerOptions for FormatText and FormatJSON.
+func structuredOptions() *slog.HandlerOptions {
+	return &slog.HandlerOptions{
+		AddSource:	true,
+		Level:		LevelTrace,	//	tRootHandler does the filtering
+		ReplaceAttr:	func(xGroups []string, attr slog.Attr) slog.Attr {
+			if 0 == len(xGroups) && slog.LevelKey == attr.Key {
+				if level, ok := attr.Value.Any().(slog.Level); ok {
+					attr.Value = slog.StringValue(LevelName(level))
+				}
+			}
+			return attr
+		},
+	}
+}
+
+//	levelIndex maps any slog.Level onto the nearest of our five levels at or below it.
+func levelIndex(level slog.Level) int {
+	switch {
+	case level >= LevelError:
+		return kIndexError
+	case level >= LevelWarning:
+		return kIndexWarning
+	case level >= LevelInfo:
+		return kIndexInfo
+	case level >= LevelDebug:
+		return kIndexDebug
+	}
+	return kIndexTrace
+}
+
+//	LevelName returns the name used for level in log output, e.g. "WARNING".
+func LevelName(level slog.Level) string {
+	return xLevelNames[levelIndex(level)]
+}
+
+//	appendHeader formats the timestamp and file name the way package log does.
+func appendHeader(xBuf []byte, r slog.Record, flags int) []byte {
+	if 0 != flags & (log.Ldate|log.Ltime|log.Lmicroseconds) {
+		t := r.Time
+		if 0 != flags & log.LUTC {
+			t = t.UTC()
+		}
+		if 0 != flags & log.Ldate {
+			xBuf = t.AppendFormat(xBuf, `2006/01/02 `)
+		}
+		if 0 != flags & (log.Ltime|log.Lmicroseconds) {
+			if 0 != flags & log.Lmicroseconds {
+				xBuf = t.AppendFormat(xBuf, `15:04:05.000000 `)
+			} else {
+				xBuf = t.AppendFormat(xBuf, `15:04:05 `)
+			}
+		}
+	}
+
+	if 0 != flags & (log.Lshortfile|log.Llongfile) {
+		file, line := `???`, 0
+		if 0 != r.PC {
+			frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+			file, line = frame.File, frame.Line
+		}
+		if 0 != flags & log.Lshortfile {
+			file = filepath.Base(file)
+		}
+		xBuf = append(xBuf, file...)
+		xBuf = append(xBuf, ':')
+		xBuf = strconv.AppendInt(xBuf, int64(line), 10)
+		xBuf = append(xBuf, `: `...)
+	}
+	return xBuf
+}
+
+//	appendAttr appends " key=value", flattening groups into dotted keys.
+func appendAttr(xBuf []byte, group string, attr slog.Attr) []byte {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return xBuf
+	}
+
+	if slog.KindGroup == attr.Value.Kind() {
+		if 0 != len(attr.Key) {
+			group += attr.Key + `.`
+		}
+		for _, member := range attr.Value.Group() {
+			xBuf = appendAttr(xBuf, group, member)
+		}
+		return xBuf
+	}
+
+	xBuf = append(xBuf, ' ')
+	xBuf = append(xBuf, group...)
+	xBuf = append(xBuf, attr.Key...)
+	xBuf = append(xBuf, '=')
+
+	var value string
+	if slog.KindTime == attr.Value.Kind() {
+		value = attr.Value.Time().Format(time.RFC3339Nano)
+	} else {
+		value = attr.Value.String()
+	}
+	if 0 == len(value) || strings.ContainsAny(value, " \t\r\n=\"") {
+		xBuf = strconv.AppendQuote(xBuf, value)
+	} else {
+		xBuf = append(xBuf, value...)
+	}
+	return xBuf
+}
+
@@ -0,0 +1,125 @@
+package loggers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	//	KeyRequestID is the attribute key AWS Lambda's JSON log format uses for the request ID, e.g.
+	//		ctx = loggers.WithContext(ctx, loggers.KeyRequestID, lambdaContext.AwsRequestID)
+	KeyRequestID	= `requestId`
+
+	//	set by the Lambda runtime; its presence means we're running in Lambda
+	kEnvLambdaFunctionName	= `AWS_LAMBDA_FUNCTION_NAME`
+	//	set by the Lambda runtime when the function's log level is configured
+	kEnvLambdaLogLevel		= `AWS_LAMBDA_LOG_LEVEL`
+)
+
+//	set by SetRequestID(); nil when there is none
+var pRequestID atomic.Pointer[string]
+
+/*	tLevelsHandler passes each record to the handler for its level, so that FormatLambda can write
+	the levels to the different writers given to Init().
+*/
+type tLevelsHandler struct {
+	xHandlers	[kLevelCount]slog.Handler
+}
+
+func (h tLevelsHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h tLevelsHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.xHandlers[levelIndex(r.Level)].Handle(ctx, r)
+}
+
+func (h tLevelsHandler) WithAttrs(xAttrs []slog.Attr) slog.Handler {
+	for i := range h.xHandlers {
+		h.xHandlers[i] = h.xHandlers[i].WithAttrs(xAttrs)
+	}
+	return h
+}
+
+func (h tLevelsHandler) WithGroup(name string) slog.Handler {
+	for i := range h.xHandlers {
+		h.xHandlers[i] = h.xHandlers[i].WithGroup(name)
+	}
+	return h
+}
+
+//	tLockedWriter serializes the writes of the per-level handlers, which may share a writer.
+type tLockedWriter struct {
+	pMutex	*sync.Mutex
+	writer	io.Writer
+}
+
+func (w tLockedWriter) Write(xBytes []byte) (int, error) {
+	w.pMutex.Lock()
+	defer w.pMutex.Unlock()
+	return w.writer.Write(xBytes)
+}
+
+//\\//	functions
+
+/*	SetRequestID sets the request ID that lines printed through the *log.Logger variables carry under
+	KeyRequestID, e.g. at the start of each invocation of a Lambda handler.  An empty id clears it.
+	Slog() and the loggers from FromContext() carry only what WithContext() gave them.
+*/
+func SetRequestID(id string) {
+	if 0 == len(id) {
+		pRequestID.Store(nil)
+	} else {
+		pRequestID.Store(&id)
+	}
+}
+
+//	lambdaHandler returns a handler writing FormatLambda, each level to its own writer (nil discards).
+func lambdaHandler(xWriters [kLevelCount]io.Writer) slog.Handler {
+	var h tLevelsHandler
+	pMutex := new(sync.Mutex)
+	for i, w := range xWriters {
+		if nil == w {
+			w = io.Discard
+		}
+		h.xHandlers[i] = slog.NewJSONHandler(tLockedWriter{pMutex: pMutex, writer: w}, lambdaOptions())
+	}
+	return h
+}
+
+/*	lambdaOptions returns the slog.HandlerOptions for FormatLambda, which renames slog's keys to those
+	CloudWatch expects of Lambda's JSON log format, so that its advanced logging controls (such as filtering
+	by level) work.  The timestamp is dropped, since CloudWatch records its own.
+*/
+func lambdaOptions() *slog.HandlerOptions {
+	return &slog.HandlerOptions{
+		Level:			LevelTrace,	//	tRootHandler does the filtering
+		ReplaceAttr:	func(xGroups []string, attr slog.Attr) slog.Attr {
+			if 0 != len(xGroups) {
+				return attr
+			}
+			switch attr.Key {
+			case slog.TimeKey:
+				return slog.Attr{}
+			case slog.MessageKey:
+				attr.Key = `message`
+			case slog.LevelKey:
+				if level, ok := attr.Value.Any().(slog.Level); ok {
+					attr.Value = slog.StringValue(lambdaLevelName(level))
+				}
+			}
+			return attr
+		},
+	}
+}
+
+//	lambdaLevelName returns the name Lambda uses for level, which differs from ours only in WARN.
+func lambdaLevelName(level slog.Level) (name string) {
+	if name = LevelName(level); kIndexWarning == levelIndex(level) {
+		name = `WARN`
+	}
+	return
+}
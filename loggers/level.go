@@ -0,0 +1,106 @@
+package loggers
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+//	the environment variable from which the minimum level is read at startup
+const kEnvLogLevel = `LOG_LEVEL`
+
+var (
+	//	minLevel is the minimum level logged; it defaults to LevelInfo.
+	minLevel	slog.LevelVar
+
+	//	keeps minLevel and the outputs of the *log.Logger variables in step
+	mutexLevel	sync.Mutex
+)
+
+/*	SetLevel sets the minimum level logged by every logger in the package.
+	It is atomic, so it may be called at any time, e.g. from a signal handler or a config reload.
+*/
+func SetLevel(level slog.Level) {
+	ensureSetup()
+
+	mutexLevel.Lock()
+	defer mutexLevel.Unlock()
+	minLevel.Set(level)
+	syncOutputs()
+}
+
+//	GetLevel returns the minimum level currently logged.
+func GetLevel() slog.Level {
+	ensureSetup()
+	return minLevel.Level()
+}
+
+//	Enabled reports whether anything at level would be logged, e.g. to skip building an expensive message.
+func Enabled(level slog.Level) bool {
+	return level >= GetLevel()
+}
+
+/*	syncOutputs points each *log.Logger variable below the minimum level at io.Discard, which package log
+	recognizes and returns on before formatting anything, so that disabled Debug and Trace calls cost next to
+	nothing.  The caller must hold mutexLevel.
+*/
+func syncOutputs() {
+	for i, pLogger := range [kLevelCount]*log.Logger{Trace, Debug, Info, Warning, Error} {
+		pLogger.SetOutput(levelOutput(xLevels[i], minLevel.Level()))
+	}
+}
+
+//	levelOutput returns the output for the *log.Logger variable at level, given the minimum level.
+func levelOutput(level slog.Level, min slog.Level) io.Writer {
+	if level < min {
+		return io.Discard
+	}
+	return tLevelWriter{level}
+}
+
+/*	initialOutput is levelOutput() for the minimum level setup() will find in the environment, read silently
+	here since the *log.Logger variables are created before setup() runs.
+*/
+func initialOutput(level slog.Level) io.Writer {
+	min, _, _ := envLevel()
+	return levelOutput(level, min)
+}
+
+/*	envLevel returns the minimum level set in the environment, or LevelInfo, along with the name of the variable
+	it came from: LOG_LEVEL, or in AWS Lambda, AWS_LAMBDA_LOG_LEVEL as set by its advanced logging controls
+	unless LOG_LEVEL is also set.
+*/
+func envLevel() (level slog.Level, name string, err error) {
+	name = kEnvLogLevel
+	if 0 != len(os.Getenv(kEnvLambdaFunctionName)) && 0 == len(os.Getenv(kEnvLogLevel)) {
+		name = kEnvLambdaLogLevel
+	}
+
+	level = LevelInfo
+	if value := os.Getenv(name); 0 != len(value) {
+		if level, err = ParseLevel(value); nil != err {
+			level = LevelInfo
+		}
+	}
+	return
+}
+
+/*	ParseLevel accepts the level names used in log output (TRACE, DEBUG, INFO, WARNING, ERROR),
+	case-insensitively, as well as WARN and slog's offset forms such as "DEBUG+2".
+*/
+func ParseLevel(s string) (level slog.Level, err error) {
+	name := strings.ToUpper(strings.TrimSpace(s))
+	for i, levelName := range xLevelNames {
+		if levelName == name {
+			return xLevels[i], nil
+		}
+	}
+	if err = level.UnmarshalText([]byte(name)); nil != err {
+		err = fmt.Errorf(`Unknown log level "%s"`, s)
+	}
+	return
+}
@@ -1,20 +1,78 @@
+/*	Package loggers implements a set of loggers with severity levels.
+	The *log.Logger variables Info, Warning, Error, Debug and Trace remain the simplest way in, while
+	Slog() returns a *slog.Logger for structured logging with key/value attributes.  Both feed the same
+	handler, so they share one output format, chosen by Init() or InitStructured().
+*/
 package loggers
 
 import (
-	"log"
+	"fmt"
 	"io"
+	"log"
+	"log/slog"
 	"os"
+	"strconv"
+)
+
+//\\//	package-scope constants and variables
+
+//	Severity levels, in slog terms.  LevelTrace extends slog's own levels below Debug.
+const (
+	LevelTrace		= slog.Level(-8)
+	LevelDebug		= slog.LevelDebug
+	LevelInfo		= slog.LevelInfo
+	LevelWarning	= slog.LevelWarn
+	LevelError		= slog.LevelError
 )
 
 var (
-	// these package-level variables are exported to any package importing this one
-	Info	*log.Logger
-	Warning	*log.Logger
-	Error	*log.Logger
-//	Trace	*log.Logger
+	/*	these package-level variables are exported to any package importing this one
+		They are never reassigned: Init() and friends change where they write, not the variables
+		themselves, so reconfiguring is safe while other goroutines are logging.
+	*/
+	Info	= log.New(initialOutput(LevelInfo), ``, 0)
+	Warning	= log.New(initialOutput(LevelWarning), ``, 0)
+	Error	= log.New(initialOutput(LevelError), ``, 0)
+	Debug	= log.New(initialOutput(LevelDebug), ``, 0)
+	Trace	= log.New(initialOutput(LevelTrace), ``, 0)
 )
 
-func init() {
+//\\//	functions
+
+/*	Init sets up the classic plain-text format: each line starts with a severity prefix and a timestamp,
+	and Warning and Error lines also name the file and line number.
+	Debug and Trace lines go to infoWriter, but only once SetLevel() (or LOG_LEVEL) lowers the minimum level.
+	Init may be called at any time, even while other goroutines are logging.
+	Use Configure() for UTC or microsecond timestamps, other prefixes, or other writers per level.
+	In AWS Lambda, Init keeps FormatLambda, writing each level to its writer; Configure() still switches to the
+	classic format there.
+*/
+func Init(infoWriter io.Writer, warningWriter io.Writer, errorWriter io.Writer/*, traceWriter io.Writer*//*, bUTC bool*/) {
+	if 0 != len(os.Getenv(kEnvLambdaFunctionName)) {
+		setHandler(lambdaHandler([kLevelCount]io.Writer{
+			kIndexTrace:	infoWriter,
+			kIndexDebug:	infoWriter,
+			kIndexInfo:		infoWriter,
+			kIndexWarning:	warningWriter,
+			kIndexError:	errorWriter,
+		}))
+		return
+	}
+
+	pConfig := DefaultConfig()
+	pConfig.Trace.Writer	= infoWriter
+	pConfig.Debug.Writer	= infoWriter
+	pConfig.Info.Writer		= infoWriter
+	pConfig.Warning.Writer	= warningWriter
+	pConfig.Error.Writer	= errorWriter
+	Configure(pConfig)
+}
+
+/*	setup establishes the defaults the first time anything in the package is used, rather than in an
+	init() that would run (and used to print) in every program importing the package, whether it logs or not.
+	Since it runs inside ensureSetup(), it must not call anything that calls ensureSetup().
+*/
+func setup() {
 	/*	This will enable calls to loggers.(Info|Warning|Error).Whatever() to work as if log.Whatever() was called
 		until Init() is called.
 	*/
@@ -25,19 +83,59 @@ func init() {
 //	Trace	= p
 */
 	//	Better yet, let's just do this to tide us over until the "main" package (possibly) calls Init() with different arguments.
-	Init(os.Stdout, os.Stdout, os.Stderr)
-	Info.Println(`Executing loggers.init()`)
-}
+	//	In AWS Lambda, start out in FormatLambda instead.
+	if 0 != len(os.Getenv(kEnvLambdaFunctionName)) {
+		pCurrent.Store(&tCurrent{handler: formatHandler(os.Stdout, FormatLambda)})
+	} else {
+		pCurrent.Store(&tCurrent{handler: configHandler(DefaultConfig())})
+	}
 
-func Init(infoWriter io.Writer, warningWriter io.Writer, errorWriter io.Writer/*, traceWriter io.Writer*//*, bUTC bool*/) {
-	flags := log.LstdFlags	//	LstdFlags = Ldate | Ltime
-/*	if bUTC {
-		flags |= log.LUTC
+	/*	The minimum level may be set from the environment, e.g. LOG_LEVEL=debug.  The *log.Logger variables
+		were created with outputs to match (see envLevel()), so there is no syncOutputs() to do here, which is
+		as well, since setup() may be running inside one of their Write()s.
+	*/
+	if level, name, err := envLevel(); nil == err {
+		minLevel.Set(level)
+	} else {
+		logDirect(LevelWarning, fmt.Sprintf(`Ignoring %s: %v`, name, err))
+	}
+
+	if value := os.Getenv(kEnvDiagnostics); 0 != len(value) {
+		b, _ := strconv.ParseBool(value)
+		bDiagnostics.Store(b)
+	}
+	if bDiagnostics.Load() {
+		logDirect(LevelInfo, `DIAGNOSTIC: loggers initialized`)
 	}
+}
+
+/*	InitStructured switches every logger to one of the structured formats, writing to w, and suppresses
+	anything below level.  The *log.Logger variables keep working: each line they print becomes the
+	message of a record at their level.
 */
-	//func New(out io.Writer, prefix string, flag int) *Logger
-	Info	= log.New(infoWriter, `INFO: `, flags)
-	Warning	= log.New(warningWriter, `WARNING: `, flags|log.Lshortfile)
-	Error	= log.New(errorWriter, `ERROR: `, flags|log.Lshortfile)
-//	Trace	= log.New(traceWriter, `TRACE: `, flags|log.Lshortfile)
+func InitStructured(w io.Writer, format Format, level slog.Level) {
+	setHandler(formatHandler(w, format))
+	SetLevel(level)
+}
+
+//	formatHandler returns a handler writing format to w.
+func formatHandler(w io.Writer, format Format) (h slog.Handler) {
+	switch format {
+	case FormatText:
+		h = slog.NewTextHandler(w, structuredOptions())
+	case FormatJSON:
+		h = slog.NewJSONHandler(w, structuredOptions())
+	case FormatLambda:
+		h = slog.NewJSONHandler(w, lambdaOptions())
+	default:
+		pConfig := DefaultConfig()
+		pConfig.Trace.Writer, pConfig.Debug.Writer, pConfig.Info.Writer, pConfig.Warning.Writer, pConfig.Error.Writer = w, w, w, w, w
+		h = configHandler(pConfig)
+	}
+	return
+}
+
+//	Slog returns a structured logger writing through the same handler as the *log.Logger variables.
+func Slog() *slog.Logger {
+	return pSlog
 }
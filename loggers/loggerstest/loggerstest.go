@@ -0,0 +1,202 @@
+/*	Package loggerstest captures what package loggers writes, so that tests can assert on it.
+	Call Capture() at the top of a test; everything logged until the test ends is recorded in memory
+	instead of being written out.
+*/
+package loggerstest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/imtlab/pkg/loggers"
+)
+
+//\\//	package-scope constants and variables
+
+var (
+	//	held from Capture() until the test's cleanup, since the loggers are package-wide
+	mutexCapture	sync.Mutex
+
+	//	the name of the test holding mutexCapture, if any
+	mutexOwner		sync.Mutex
+	owner			string
+)
+
+//\\//	type definitions (and attached methods)
+
+//	An Entry is one record captured by a Recorder.  Attribute keys are qualified by their groups, e.g. "req.id".
+type Entry struct {
+	Level	slog.Level
+	Message	string
+	xAttrs	[]slog.Attr
+}
+
+//	Attrs returns the entry's attributes, including those added with With().
+func (e Entry) Attrs() []slog.Attr {
+	return append([]slog.Attr(nil), e.xAttrs...)
+}
+
+//	String renders the entry as "LEVEL message key=value ...", which is what Contains() searches.
+func (e Entry) String() string {
+	var sb strings.Builder
+	sb.WriteString(loggers.LevelName(e.Level))
+	sb.WriteString(` `)
+	sb.WriteString(e.Message)
+	for _, attr := range e.xAttrs {
+		fmt.Fprintf(&sb, ` %s=%v`, attr.Key, attr.Value)
+	}
+	return sb.String()
+}
+
+//	A Recorder holds the entries captured since Capture() returned it.
+type Recorder struct {
+	sync.Mutex
+	xEntries	[]Entry
+}
+
+//	Entries returns the entries captured at exactly level, in the order they were logged.
+func (p *Recorder) Entries(level slog.Level) (xEntries []Entry) {
+	p.Lock()
+	defer p.Unlock()
+
+	for _, entry := range p.xEntries {
+		if level == entry.Level {
+			xEntries = append(xEntries, entry)
+		}
+	}
+	return
+}
+
+//	All returns every entry captured, in the order they were logged.
+func (p *Recorder) All() []Entry {
+	p.Lock()
+	defer p.Unlock()
+	return append([]Entry(nil), p.xEntries...)
+}
+
+//	Contains reports whether any entry captured, rendered by Entry.String(), contains substr.
+func (p *Recorder) Contains(substr string) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	for _, entry := range p.xEntries {
+		if strings.Contains(entry.String(), substr) {
+			return true
+		}
+	}
+	return false
+}
+
+//	Reset discards the entries captured so far.
+func (p *Recorder) Reset() {
+	p.Lock()
+	defer p.Unlock()
+	p.xEntries = nil
+}
+
+func (p *Recorder) add(entry Entry) {
+	p.Lock()
+	defer p.Unlock()
+	p.xEntries = append(p.xEntries, entry)
+}
+
+//	tHandler is the slog.Handler installed by Capture().
+type tHandler struct {
+	pRecorder	*Recorder
+	xAttrs		[]slog.Attr		//	from WithAttrs(), already qualified
+	group		string			//	"group." prefix for subsequent keys
+}
+
+func (h tHandler) Enabled(context.Context, slog.Level) bool {
+	return true		//	the loggers package does the filtering
+}
+
+func (h tHandler) Handle(_ context.Context, r slog.Record) error {
+	xAttrs := append([]slog.Attr(nil), h.xAttrs...)
+	r.Attrs(func(attr slog.Attr) bool {
+		xAttrs = appendAttr(xAttrs, h.group, attr)
+		return true
+	})
+	h.pRecorder.add(Entry{Level: r.Level, Message: r.Message, xAttrs: xAttrs})
+	return nil
+}
+
+func (h tHandler) WithAttrs(xAttrs []slog.Attr) slog.Handler {
+	xOut := append([]slog.Attr(nil), h.xAttrs...)
+	for _, attr := range xAttrs {
+		xOut = appendAttr(xOut, h.group, attr)
+	}
+	h.xAttrs = xOut
+	return h
+}
+
+func (h tHandler) WithGroup(name string) slog.Handler {
+	h.group += name + `.`
+	return h
+}
+
+//\\//	functions
+
+/*	Capture makes every logger in package loggers record into the returned Recorder, at every level down to
+	loggers.LevelTrace, until t ends, when the previous handler and minimum level are restored.
+	Tests that capture take turns, since the loggers are package-wide: each holds the loggers from Capture()
+	until it ends, and the others wait.  So a parallel test must call t.Parallel() before Capture(), never
+	after, or it will pause holding the loggers and the next test to capture will wait forever.  For the same
+	reason, a test may not capture twice, nor in a subtest of a test that captures; Capture fails such a test
+	at once.
+*/
+func Capture(t testing.TB) (p *Recorder) {
+	t.Helper()
+
+	name := t.Name()
+	mutexOwner.Lock()
+	current := owner
+	mutexOwner.Unlock()
+	if 0 != len(current) && (current == name || strings.HasPrefix(name, current + `/`)) {
+		t.Fatalf(`loggerstest: Capture() called in %s while %s is already capturing`, name, current)
+	}
+
+	mutexCapture.Lock()
+	mutexOwner.Lock()
+	owner = name
+	mutexOwner.Unlock()
+
+	p = new(Recorder)
+	previousLevel := loggers.GetLevel()
+	previous := loggers.SetHandler(tHandler{pRecorder: p})
+	loggers.SetLevel(loggers.LevelTrace)
+
+	t.Cleanup(func() {
+		loggers.SetHandler(previous)
+		loggers.SetLevel(previousLevel)
+
+		mutexOwner.Lock()
+		owner = ``
+		mutexOwner.Unlock()
+		mutexCapture.Unlock()
+	})
+	return
+}
+
+//	appendAttr appends attr, qualifying its key with group and flattening any group it holds.
+func appendAttr(xAttrs []slog.Attr, group string, attr slog.Attr) []slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	if slog.KindGroup == attr.Value.Kind() {
+		if 0 != len(attr.Key) {
+			group += attr.Key + `.`
+		}
+		for _, member := range attr.Value.Group() {
+			xAttrs = appendAttr(xAttrs, group, member)
+		}
+		return xAttrs
+	}
+	if 0 == len(attr.Key) {
+		return xAttrs	//	slog ignores empty attributes
+	}
+	attr.Key = group + attr.Key
+	return append(xAttrs, attr)
+}
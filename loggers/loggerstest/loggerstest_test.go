@@ -0,0 +1,89 @@
+package loggerstest_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imtlab/pkg/loggers"
+	"github.com/imtlab/pkg/loggers/loggerstest"
+)
+
+func TestCapture(t *testing.T) {
+	levelBefore := loggers.GetLevel()
+	previous := loggers.SetHandler(slog.DiscardHandler)	//	so that nothing leaks out of the test
+	t.Cleanup(func() {
+		loggers.SetHandler(previous)
+	})
+
+	var pRecorder *loggerstest.Recorder
+	t.Run(`capture`, func(t *testing.T) {
+		pRecorder = loggerstest.Capture(t)
+		loggers.Debug.Println(`debug`)
+		loggers.Slog().WithGroup(`req`).With(`id`, 7).Error(`failed`, `code`, 500)
+
+		if 1 != len(pRecorder.Entries(loggers.LevelDebug)) {
+			t.Fatal(`Debug was not captured`)
+		}
+		xEntries := pRecorder.Entries(loggers.LevelError)
+		if 1 != len(xEntries) || `ERROR failed req.id=7 req.code=500` != xEntries[0].String() {
+			t.Fatalf(`Error entries = %v`, xEntries)
+		}
+		if !pRecorder.Contains(`req.code=500`) || pRecorder.Contains(`nothing like it`) {
+			t.Fatal(`Contains() is wrong`)
+		}
+
+		pRecorder.Reset()
+		if 0 != len(pRecorder.All()) {
+			t.Fatal(`Reset() kept entries`)
+		}
+	})
+
+	if levelBefore != loggers.GetLevel() {
+		t.Fatalf(`level = %v after the capture ended; want %v`, loggers.GetLevel(), levelBefore)
+	}
+	loggers.Error.Println(`after`)
+	if pRecorder.Contains(`after`) {
+		t.Fatal(`the recorder was still capturing after its test ended`)
+	}
+}
+
+func TestCaptureParallel(t *testing.T) {
+	for _, name := range []string{`a`, `b`, `c`} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			pRecorder := loggerstest.Capture(t)
+			loggers.Info.Println(name)
+			if xEntries := pRecorder.All(); 1 != len(xEntries) || name != xEntries[0].Message {
+				t.Fatalf(`entries = %v; want only %s`, xEntries, name)
+			}
+		})
+	}
+}
+
+//	kEnvNested makes TestCaptureNested capture twice in the subprocess it runs itself in.
+const kEnvNested = `LOGGERSTEST_TEST_NESTED`
+
+func TestCaptureNested(t *testing.T) {
+	if `1` == os.Getenv(kEnvNested) {
+		loggerstest.Capture(t)
+		t.Run(`sub`, func(t *testing.T) {
+			loggerstest.Capture(t)
+		})
+		return
+	}
+
+	//	without the check, the subtest would wait forever for its parent to release the loggers
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	pCmd := exec.CommandContext(ctx, os.Args[0], `-test.run=^TestCaptureNested$`)
+	pCmd.Env = append(os.Environ(), kEnvNested + `=1`)
+	xBytes, err := pCmd.CombinedOutput()
+	if nil == err || nil != ctx.Err() || !strings.Contains(string(xBytes), `TestCaptureNested is already capturing`) {
+		t.Fatalf(`the subprocess ended with %v, writing %q; want it to fail at once`, err, xBytes)
+	}
+}
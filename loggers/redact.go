@@ -0,0 +1,165 @@
+package loggers
+
+import (
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	//	what redacted text is replaced with
+	kRedacted	= `[REDACTED]`
+
+	//	shorter secrets are not registered, since masking them would garble unrelated text
+	kMinSecretLength	= 4
+)
+
+//	tRedaction is an immutable set of things to redact, replaced wholesale whenever one is registered.
+type tRedaction struct {
+	xSecrets	[]string
+	pReplacer	*strings.Replacer	//	nil until a secret is registered
+	xPatterns	[]*regexp.Regexp	//	registered with RegisterPattern(), in addition to the defaults
+}
+
+var (
+	//	serializes registrations; reads go through pRedaction without locking
+	mutexRedaction	sync.Mutex
+	pRedaction		atomic.Pointer[tRedaction]	//	nil until something is registered
+)
+
+/*	getDefaultPatterns returns the patterns redacted whether or not anything is registered.
+	They are compiled on first use, so that importing the package costs nothing.
+*/
+var getDefaultPatterns = sync.OnceValue(func() []*regexp.Regexp {
+	return []*regexp.Regexp{
+		//	AWS access key IDs
+		regexp.MustCompile(`\b(?:AKIA|ASIA|AIDA|AROA)[0-9A-Z]{16}\b`),
+		//	bearer tokens, e.g. in an Authorization header
+		regexp.MustCompile(`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9\-._~+/]+=*)`),
+		//	password=..., token: ..., aws_secret_access_key = ... and the like
+		regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|secret|token|api[_-]?key|[a-z_]*secret_access_key)\s*[=:]\s*(?P<secret>"[^"]*"|[^\s"&,;]+)`),
+	}
+})
+
+/*	RegisterSecret arranges for each of xValues to be masked wherever it appears in log output, e.g.
+	passwords and keys read from the environment.  Values shorter than four characters are ignored.
+*/
+func RegisterSecret(xValues ...string) {
+	mutexRedaction.Lock()
+	defer mutexRedaction.Unlock()
+
+	old := loadRedaction()
+	xSecrets := append([]string(nil), old.xSecrets...)
+	for _, value := range xValues {
+		if kMinSecretLength <= len(value) {
+			xSecrets = append(xSecrets, value)
+		}
+	}
+
+	//	mask longer secrets first, in case one contains another
+	sort.Slice(xSecrets, func(i, j int) bool {
+		return len(xSecrets[i]) > len(xSecrets[j])
+	})
+	xOldNew := make([]string, 0, 2 * len(xSecrets))
+	for _, secret := range xSecrets {
+		xOldNew = append(xOldNew, secret, kRedacted)
+	}
+
+	pRedaction.Store(&tRedaction{xSecrets: xSecrets, pReplacer: strings.NewReplacer(xOldNew...), xPatterns: old.xPatterns})
+}
+
+/*	RegisterPattern arranges for matches of pattern to be masked in log output.  If pattern has a
+	subexpression named "secret", only that part of each match is masked.
+*/
+func RegisterPattern(pattern *regexp.Regexp) {
+	mutexRedaction.Lock()
+	defer mutexRedaction.Unlock()
+
+	redaction := *loadRedaction()
+	redaction.xPatterns = append(append([]*regexp.Regexp(nil), redaction.xPatterns...), pattern)
+	pRedaction.Store(&redaction)
+}
+
+//	Redact returns s with every registered secret and pattern masked, as it would appear in log output.
+func Redact(s string) string {
+	pRedaction := loadRedaction()
+
+	if nil != pRedaction.pReplacer {
+		s = pRedaction.pReplacer.Replace(s)
+	}
+	for _, pattern := range getDefaultPatterns() {
+		s = redactPattern(s, pattern)
+	}
+	for _, pattern := range pRedaction.xPatterns {
+		s = redactPattern(s, pattern)
+	}
+	return s
+}
+
+func loadRedaction() (p *tRedaction) {
+	if p = pRedaction.Load(); nil == p {
+		p = new(tRedaction)
+	}
+	return
+}
+
+func redactPattern(s string, pattern *regexp.Regexp) string {
+	index := pattern.SubexpIndex(`secret`)
+	if 0 > index {
+		return pattern.ReplaceAllLiteralString(s, kRedacted)
+	}
+
+	//	mask only the secret subexpression of each match
+	var sb strings.Builder
+	last := 0
+	for _, xLoc := range pattern.FindAllStringSubmatchIndex(s, -1) {
+		if start, end := xLoc[2 * index], xLoc[2 * index + 1]; 0 <= start {
+			sb.WriteString(s[last:start])
+			sb.WriteString(kRedacted)
+			last = end
+		}
+	}
+	if 0 == last {
+		return s
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+//	redactRecord returns a copy of r with its message and attributes redacted.
+func redactRecord(r slog.Record) slog.Record {
+	r2 := slog.NewRecord(r.Time, r.Level, Redact(r.Message), r.PC)
+	r.Attrs(func(attr slog.Attr) bool {
+		r2.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return r2
+}
+
+func redactAttrs(xAttrs []slog.Attr) []slog.Attr {
+	xOut := make([]slog.Attr, len(xAttrs))
+	for i, attr := range xAttrs {
+		xOut[i] = redactAttr(attr)
+	}
+	return xOut
+}
+
+/*	redactAttr redacts string values, and anything else that renders as a string containing a secret
+	(e.g. an error), in which case the value is replaced by its redacted rendering.
+*/
+func redactAttr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	switch attr.Value.Kind() {
+	case slog.KindGroup:
+		attr.Value = slog.GroupValue(redactAttrs(attr.Value.Group())...)
+	case slog.KindString, slog.KindAny:
+		s := attr.Value.String()
+		if redacted := Redact(s); redacted != s {
+			attr.Value = slog.StringValue(redacted)
+		}
+	}
+	return attr
+}
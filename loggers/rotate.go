@@ -0,0 +1,333 @@
+package loggers
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+//	the timestamp inserted into the names of rotated files; it sorts chronologically
+const kRotateTimeFormat = `20060102T150405.000`
+
+//	TRotateConfig says when a TRotatingFile rotates and what it keeps.  Zero values disable each feature.
+type TRotateConfig struct {
+	MaxSize		int64			//	rotate before a write would take the file past this many bytes
+	MaxAge		time.Duration	//	rotate once the file has been open this long
+	MaxBackups	int				//	delete the oldest rotated files beyond this many
+	Compress	bool			//	gzip rotated files
+}
+
+/*	A TRotatingFile is an io.Writer appending to a file that it rotates by size and by age, for passing to
+	Init() or InitStructured() in long-running programs.  A rotated file is renamed by inserting a timestamp
+	before its extension (e.g. app.log becomes app-20261017T150405.000.log) and optionally gzipped.
+	It can also reopen its file on demand (see ReopenOnSignal()) for use with an external logrotate.
+	Create one with NewRotatingFile().
+*/
+type TRotatingFile struct {
+	sync.Mutex
+	path		string
+	config		TRotateConfig
+	pFile		*os.File
+	size		int64
+	opened		time.Time
+	xPending	[]string		//	rotated files awaiting compression and pruning
+	bCleaning	bool			//	whether cleanup() is running
+	wgCleanup	sync.WaitGroup	//	lets Close() wait for cleanup()
+}
+
+func (p *TRotatingFile) Write(xBytes []byte) (n int, err error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if nil == p.pFile {
+		return 0, os.ErrClosed
+	}
+
+	if p.due(int64(len(xBytes))) {
+		if err = p.rotate(); nil != err {
+			return
+		}
+	}
+
+	n, err = p.pFile.Write(xBytes)
+	p.size += int64(n)
+	return
+}
+
+//	Rotate rotates the file now, regardless of its size and age.
+func (p *TRotatingFile) Rotate() error {
+	p.Lock()
+	defer p.Unlock()
+	return p.rotate()
+}
+
+//	Reopen closes and reopens the file, e.g. after an external tool has moved it aside.
+func (p *TRotatingFile) Reopen() (err error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if nil != p.pFile {
+		p.pFile.Close()
+	}
+	return p.open()
+}
+
+/*	ReopenOnSignal reopens the file whenever the process receives one of xSignals, or SIGHUP if none are
+	given, until the returned stop function is called.
+*/
+func (p *TRotatingFile) ReopenOnSignal(xSignals ...os.Signal) (stop func()) {
+	if 0 == len(xSignals) {
+		xSignals = []os.Signal{syscall.SIGHUP}
+	}
+
+	chSignals := make(chan os.Signal, 1)
+	chDone := make(chan struct{})
+	signal.Notify(chSignals, xSignals...)
+
+	go func() {
+		for {
+			select {
+			case <-chSignals:
+				if err := p.Reopen(); nil != err {
+					fmt.Fprintf(os.Stderr, "ERROR: Failed to reopen %s: %v\n", p.path, err)
+				}
+			case <-chDone:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(chSignals)
+			close(chDone)
+		})
+	}
+}
+
+//	Sync commits the file's contents to stable storage.
+func (p *TRotatingFile) Sync() (err error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if nil != p.pFile {
+		err = p.pFile.Sync()
+	}
+	return
+}
+
+//	Close closes the file, after waiting for any background compression to finish.
+func (p *TRotatingFile) Close() (err error) {
+	p.Lock()
+	if nil != p.pFile {
+		err = p.pFile.Close()
+		p.pFile = nil
+	}
+	p.Unlock()
+
+	p.wgCleanup.Wait()
+	return
+}
+
+//	due reports whether the file must be rotated before writing another size bytes.  The caller must hold the lock.
+func (p *TRotatingFile) due(size int64) bool {
+	if 0 < p.config.MaxSize && 0 < p.size && p.config.MaxSize < p.size + size {
+		return true
+	}
+	return 0 < p.config.MaxAge && p.config.MaxAge <= time.Since(p.opened)
+}
+
+//	The caller must hold the lock.
+func (p *TRotatingFile) open() (err error) {
+	if p.pFile, err = os.OpenFile(p.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666); nil != err {
+		return
+	}
+
+	var finfo os.FileInfo
+	if finfo, err = p.pFile.Stat(); nil != err {
+		p.pFile.Close()
+		p.pFile = nil
+		return
+	}
+	p.size = finfo.Size()
+	p.opened = time.Now()
+	return
+}
+
+//	The caller must hold the lock.
+func (p *TRotatingFile) rotate() (err error) {
+	if nil != p.pFile {
+		p.pFile.Close()
+		p.pFile = nil
+	}
+
+	backupPath := p.backupPath(time.Now())
+	if err = os.Rename(p.path, backupPath); nil != err && !os.IsNotExist(err) {
+		err = fmt.Errorf(`Failed to rotate %s: %w`, p.path, err)
+		p.open()	//	carry on with the old file rather than lose output
+		return
+	}
+
+	if err = p.open(); nil != err {
+		return
+	}
+
+	//	hand the backup to the one background worker, starting it if need be, so that cleanups never overlap
+	p.xPending = append(p.xPending, backupPath)
+	if !p.bCleaning {
+		p.bCleaning = true
+		p.wgCleanup.Add(1)
+		go p.cleanup()
+	}
+	return
+}
+
+//	cleanup compresses and prunes after each rotation in turn, until none are pending.
+func (p *TRotatingFile) cleanup() {
+	defer p.wgCleanup.Done()
+	for {
+		p.Lock()
+		if 0 == len(p.xPending) {
+			p.bCleaning = false
+			p.Unlock()
+			return
+		}
+		backupPath := p.xPending[0]
+		p.xPending = p.xPending[1:]
+		p.Unlock()
+
+		if p.config.Compress {
+			if err := compressFile(backupPath); nil != err {
+				fmt.Fprintf(os.Stderr, "ERROR: Failed to compress %s: %v\n", backupPath, err)
+			}
+		}
+		p.prune()
+	}
+}
+
+//	prune deletes the oldest rotated files beyond MaxBackups.
+func (p *TRotatingFile) prune() {
+	if 0 >= p.config.MaxBackups {
+		return
+	}
+
+	ext := filepath.Ext(p.path)
+	xCandidates, _ := filepath.Glob(strings.TrimSuffix(p.path, ext) + `-*` + ext + `*`)
+
+	/*	The glob also matches e.g. app-access.log for app.log, so keep only names that rotate() could have made.
+		A backup caught mid-compression exists both plain and gzipped, so count backups by their timestamps.
+	*/
+	mPaths := make(map[string][]string)
+	for _, path := range xCandidates {
+		if timestamp, ok := p.backupTimestamp(path); ok {
+			mPaths[timestamp] = append(mPaths[timestamp], path)
+		}
+	}
+
+	xTimestamps := make([]string, 0, len(mPaths))
+	for timestamp := range mPaths {
+		xTimestamps = append(xTimestamps, timestamp)
+	}
+	sort.Strings(xTimestamps)	//	the timestamps sort chronologically
+	for overflow := len(xTimestamps) - p.config.MaxBackups; 0 < overflow; overflow-- {
+		for _, path := range mPaths[xTimestamps[0]] {
+			os.Remove(path)
+		}
+		xTimestamps = xTimestamps[1:]
+	}
+}
+
+/*	backupPath returns the name to rotate the file to at instant t.  Should a backup by that name already
+	exist, from a rotation within the same millisecond, t is moved on until the name is free.
+	The caller must hold the lock.
+*/
+func (p *TRotatingFile) backupPath(t time.Time) (path string) {
+	ext := filepath.Ext(p.path)
+	for {
+		path = strings.TrimSuffix(p.path, ext) + `-` + t.Format(kRotateTimeFormat) + ext
+		if !exists(path) && !exists(path + `.gz`) {
+			return
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+/*	backupTimestamp reports whether path is the name of one of the file's backups, compressed or not,
+	and if so returns the timestamp in it.
+*/
+func (p *TRotatingFile) backupTimestamp(path string) (timestamp string, ok bool) {
+	ext := filepath.Ext(p.path)
+	timestamp, ok = strings.CutPrefix(strings.TrimSuffix(path, `.gz`), strings.TrimSuffix(p.path, ext) + `-`)
+	if ok {
+		timestamp, ok = strings.CutSuffix(timestamp, ext)
+	}
+	if ok {
+		_, err := time.Parse(kRotateTimeFormat, timestamp)
+		ok = nil == err
+	}
+	return
+}
+
+//\\//	functions
+
+/*	NewRotatingFile opens (or creates) path for appending, rotating it as pConfig says.
+	A nil pConfig never rotates by itself, but the file can still be rotated or reopened on demand.
+*/
+func NewRotatingFile(path string, pConfig *TRotateConfig) (p *TRotatingFile, err error) {
+	p = &TRotatingFile{path: path}
+	if nil != pConfig {
+		p.config = *pConfig
+	}
+
+	p.Lock()
+	err = p.open()
+	p.Unlock()
+
+	if nil != err {
+		p = nil
+	}
+	return
+}
+
+//	compressFile replaces path with path.gz.
+func compressFile(path string) (err error) {
+	var pSrc *os.File
+	if pSrc, err = os.Open(path); nil != err {
+		return
+	}
+	defer pSrc.Close()
+
+	var pDst *os.File
+	if pDst, err = os.Create(path + `.gz`); nil != err {
+		return
+	}
+
+	pWriter := gzip.NewWriter(pDst)
+	if _, err = io.Copy(pWriter, pSrc); nil == err {
+		err = pWriter.Close()
+	}
+	if errClose := pDst.Close(); nil == err {
+		err = errClose
+	}
+
+	if nil == err {
+		err = os.Remove(path)
+	} else {
+		os.Remove(path + `.gz`)
+	}
+	return
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return nil == err
+}
@@ -0,0 +1,137 @@
+package loggers_test
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imtlab/pkg/loggers"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, `app.log`)
+	unrelated := filepath.Join(dir, `app-access.log`)
+	if err := os.WriteFile(unrelated, nil, 0666); nil != err {
+		t.Fatal(err)
+	}
+
+	p, err := loggers.NewRotatingFile(path, &loggers.TRotateConfig{MaxSize: 10, MaxBackups: 2})
+	if nil != err {
+		t.Fatalf(`NewRotatingFile() returned error: %v`, err)
+	}
+
+	//	each line fills the file, so each write after the first rotates, several within a millisecond
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(p, "line %04d\n", i)
+	}
+	if err = p.Close(); nil != err {
+		t.Fatalf(`Close() returned error: %v`, err)
+	}
+
+	if xBytes, _ := os.ReadFile(path); "line 0004\n" != string(xBytes) {
+		t.Errorf(`%s holds %q; want the last line`, path, xBytes)
+	}
+	if _, err = os.Stat(unrelated); nil != err {
+		t.Errorf(`pruning removed %s: %v`, unrelated, err)
+	}
+
+	//	the two newest backups survive, holding the lines before the last
+	xBackups, _ := filepath.Glob(filepath.Join(dir, `app-2*.log`))
+	if 2 != len(xBackups) {
+		t.Fatalf(`backups = %v; want 2`, xBackups)
+	}
+	for i, backup := range xBackups {
+		if xBytes, _ := os.ReadFile(backup); fmt.Sprintf("line %04d\n", i + 2) != string(xBytes) {
+			t.Errorf(`%s holds %q; want line %04d`, backup, xBytes, i + 2)
+		}
+	}
+}
+
+func TestRotatingFileCompress(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, `app.log`)
+
+	//	a backup whose compression was interrupted, left both plain and gzipped, counts once
+	interrupted := filepath.Join(dir, `app-29991231T000000.000.log`)
+	for _, name := range []string{interrupted, interrupted + `.gz`} {
+		if err := os.WriteFile(name, nil, 0666); nil != err {
+			t.Fatal(err)
+		}
+	}
+
+	p, err := loggers.NewRotatingFile(path, &loggers.TRotateConfig{MaxSize: 10, MaxBackups: 3, Compress: true})
+	if nil != err {
+		t.Fatalf(`NewRotatingFile() returned error: %v`, err)
+	}
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(p, "line %04d\n", i)
+	}
+	if err = p.Close(); nil != err {
+		t.Fatalf(`Close() returned error: %v`, err)
+	}
+
+	if xPlain, _ := filepath.Glob(filepath.Join(dir, `app-2*.log`)); 1 != len(xPlain) || interrupted != xPlain[0] {
+		t.Errorf(`uncompressed backups = %v; want only %s`, xPlain, interrupted)
+	}
+
+	//	with the interrupted one, the newest, the two newest rotations survive, holding the lines before the last
+	xBackups, _ := filepath.Glob(filepath.Join(dir, `app-2*.log.gz`))
+	if 3 != len(xBackups) || interrupted + `.gz` != xBackups[2] {
+		t.Fatalf(`compressed backups = %v; want 3, the last of them %s.gz`, xBackups, interrupted)
+	}
+	for i, backup := range xBackups[:2] {
+		if got := gunzip(t, backup); fmt.Sprintf("line %04d\n", i + 2) != got {
+			t.Errorf(`%s holds %q; want line %04d`, backup, got, i + 2)
+		}
+	}
+}
+
+func gunzip(t *testing.T, path string) string {
+	t.Helper()
+	pFile, err := os.Open(path)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer pFile.Close()
+
+	pReader, err := gzip.NewReader(pFile)
+	if nil != err {
+		t.Fatalf(`%s: %v`, path, err)
+	}
+	xBytes, err := io.ReadAll(pReader)
+	if nil != err {
+		t.Fatalf(`%s: %v`, path, err)
+	}
+	return string(xBytes)
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, `app.log`)
+	p, err := loggers.NewRotatingFile(path, nil)
+	if nil != err {
+		t.Fatalf(`NewRotatingFile() returned error: %v`, err)
+	}
+	defer p.Close()
+
+	//	an external tool moves the file aside, and the next lines follow it until Reopen()
+	fmt.Fprintln(p, `before`)
+	moved := filepath.Join(dir, `app.log.1`)
+	os.Rename(path, moved)
+	fmt.Fprintln(p, `moved`)
+	if err = p.Reopen(); nil != err {
+		t.Fatalf(`Reopen() returned error: %v`, err)
+	}
+	fmt.Fprintln(p, `after`)
+
+	if xBytes, _ := os.ReadFile(moved); "before\nmoved\n" != string(xBytes) {
+		t.Errorf(`%s holds %q; want the lines before Reopen()`, moved, xBytes)
+	}
+	if xBytes, _ := os.ReadFile(path); "after\n" != string(xBytes) {
+		t.Errorf(`%s holds %q; want the line after Reopen()`, path, xBytes)
+	}
+}
@@ -0,0 +1,123 @@
+package loggers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//	set by SetSampling(); nil means every record is written
+var pSampler atomic.Pointer[tSampler]
+
+//	records are sampled by level and message, so the same text at different levels is counted separately
+type tSampleKey struct {
+	level	slog.Level
+	message	string
+}
+
+type tSample struct {
+	count		uint	//	occurrences in the current interval
+	suppressed	uint	//	how many of them were not written
+	pc			uintptr	//	where the first of them was logged from, for the summary
+}
+
+/*	tSampler keeps a fixed window per distinct message: the first occurrence opens it for interval, during
+	which only the first occurrences are written.  A timer closes it, writing a summary of whatever was suppressed.
+*/
+type tSampler struct {
+	sync.Mutex
+	first		uint
+	interval	time.Duration
+	mSamples	map[tSampleKey]*tSample
+}
+
+//	allow counts r and reports whether it should be written.
+func (p *tSampler) allow(r slog.Record) bool {
+	key := tSampleKey{level: r.Level, message: r.Message}
+
+	p.Lock()
+	defer p.Unlock()
+
+	pSample, present := p.mSamples[key]
+	if !present {
+		pSample = &tSample{pc: r.PC}
+		p.mSamples[key] = pSample
+		time.AfterFunc(p.interval, func() {
+			p.close(key)
+		})
+	}
+
+	if pSample.count++; pSample.count <= p.first {
+		return true
+	}
+	pSample.suppressed++
+	return false
+}
+
+//	close ends key's window, summarizing any occurrences that were suppressed in it.
+func (p *tSampler) close(key tSampleKey) {
+	p.Lock()
+	sample := *p.mSamples[key]
+	delete(p.mSamples, key)
+	p.Unlock()
+
+	p.summarize(key, sample)
+}
+
+/*	flush summarizes what has been suppressed so far in every open window, e.g. before the process exits.
+	The windows stay open, so what is suppressed from here on is summarized when they close.
+*/
+func (p *tSampler) flush() {
+	p.Lock()
+	mSummaries := make(map[tSampleKey]tSample)
+	for key, pSample := range p.mSamples {
+		if 0 != pSample.suppressed {
+			mSummaries[key] = *pSample
+			pSample.suppressed = 0
+		}
+	}
+	p.Unlock()
+
+	for key, sample := range mSummaries {
+		p.summarize(key, sample)
+	}
+}
+
+func (p *tSampler) summarize(key tSampleKey, sample tSample) {
+	if 0 != sample.suppressed {
+		msg := fmt.Sprintf(`Suppressed %d repeats within %v of: %s`, sample.suppressed, p.interval, key.message)
+		pCurrent.Load().handler.Handle(context.Background(), slog.NewRecord(time.Now(), key.level, msg, sample.pc))
+	}
+}
+
+//\\//	functions
+
+/*	SetSampling limits how often the same message is written, to stop a failing dependency flooding the logs:
+	of the records at the same level with the same message, only the first within each interval are written,
+	and a summary saying how many were suppressed follows at the end of the interval.
+	Attributes are not compared, and records added with With() count as the same message.
+	A first or interval of zero turns sampling off, which is the default.
+*/
+func SetSampling(first uint, interval time.Duration) {
+	if 0 == first || 0 >= interval {
+		pSampler.Store(nil)
+		return
+	}
+	pSampler.Store(&tSampler{first: first, interval: interval, mSamples: make(map[tSampleKey]*tSample)})
+}
+
+//	sampled reports whether r should be written, given the current sampling.
+func sampled(r slog.Record) bool {
+	pSampler := pSampler.Load()
+	return nil == pSampler || pSampler.allow(r)
+}
+
+//	flushSampling writes the summaries the current sampling has pending.
+func flushSampling() {
+	if pSampler := pSampler.Load(); nil != pSampler {
+		pSampler.flush()
+	}
+}
@@ -0,0 +1,148 @@
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+//	kAdaptiveDecrease is the factor by which Penalize() cuts the effective limit.
+const kAdaptiveDecrease = 0.5
+
+var _ Limiter = (*TAdaptive)(nil)
+
+/*	A TAdaptive is a sliding-log limiter whose effective limit adapts to feedback from the service
+	being called, using AIMD (additive increase, multiplicative decrease):
+	Penalize() halves the effective limit and honours any Retry-After the service supplied, while each
+	Succeed() raises it by 1/limit, i.e. by about one per window's worth of successful calls, until it is
+	back up to the configured limit, which acts as the ceiling.
+	Create one with NewAdaptive().
+*/
+type TAdaptive struct {
+	sync.Mutex
+	ceiling			uint
+	period			time.Duration
+	clock			Clock
+	effective		float64		//	the current limit, between 1 and ceiling
+	blockedUntil	time.Time	//	no slot is granted before this instant
+	xInstants		[]time.Time	//	sliding log of up to ceiling instants, oldest first
+}
+
+func (p *TAdaptive) Wait(ctx context.Context) error {
+	return waitN(ctx, p, 1)
+}
+
+func (p *TAdaptive) WaitN(ctx context.Context, n uint) error {
+	return waitN(ctx, p, n)
+}
+
+func (p *TAdaptive) TryAcquire() bool {
+	return tryAcquireN(p, 1)
+}
+
+func (p *TAdaptive) TryAcquireN(n uint) bool {
+	return tryAcquireN(p, n)
+}
+
+func (p *TAdaptive) Reserve() *Reservation {
+	return reserveN(p, 1)
+}
+
+func (p *TAdaptive) ReserveN(n uint) *Reservation {
+	return reserveN(p, n)
+}
+
+/*	Penalize reports that the service throttled a call (e.g. HTTP 429, or an AWS ThrottlingException).
+	The effective limit is cut, and if retryAfter is positive no further slot is granted until it has
+	elapsed.
+*/
+func (p *TAdaptive) Penalize(retryAfter time.Duration) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.effective *= kAdaptiveDecrease; 1 > p.effective {
+		p.effective = 1
+	}
+	if 0 < retryAfter {
+		if until := p.clock.Now().Add(retryAfter); until.After(p.blockedUntil) {
+			p.blockedUntil = until
+		}
+	}
+}
+
+//	Succeed reports that the service accepted a call, nudging the effective limit back up toward the ceiling.
+func (p *TAdaptive) Succeed() {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.effective += 1 / p.effective; float64(p.ceiling) < p.effective {
+		p.effective = float64(p.ceiling)
+	}
+}
+
+//	Limit returns the current effective limit.
+func (p *TAdaptive) Limit() uint {
+	p.Lock()
+	defer p.Unlock()
+	return uint(p.effective)
+}
+
+func (p *TAdaptive) getClock() Clock {
+	return p.clock
+}
+
+func (p *TAdaptive) checkN(n uint) (err error) {
+	if nil == p.clock {	//	guard against pinheadedness
+		err = ErrNotInitialized
+	} else if n > p.ceiling {
+		err = fmt.Errorf(`%w: n = %d; limit = %d`, ErrExceedsLimit, n, p.ceiling)
+	}
+	return
+}
+
+func (p *TAdaptive) reserve(now time.Time, n uint) (instant time.Time, undo func()) {
+	//	a call costing more than the effective limit is allowed a whole window to itself
+	limit := uint(p.effective)
+	if n > limit {
+		limit = n
+	}
+
+	var xInstants, xEvicted []time.Time
+	xInstants, instant, xEvicted = reserveLog(p.xInstants, now, n, limit, p.period)
+	if p.blockedUntil.After(instant) {
+		instant = p.blockedUntil
+		for i := len(xInstants) - int(n); i < len(xInstants); i++ {
+			xInstants[i] = instant
+		}
+	}
+
+	//	keep what reserveLog() evicted for the effective limit, up to the ceiling, in case the limit rises again
+	p.xInstants = append(xEvicted, xInstants...)
+	if overflow := len(p.xInstants) - int(p.ceiling); 0 < overflow {
+		p.xInstants = p.xInstants[overflow:]
+	}
+
+	undo = func() {
+		p.xInstants = unreserveLog(p.xInstants, instant, n, nil, p.ceiling)
+	}
+	return
+}
+
+//\\//	functions
+
+/*	NewAdaptive returns an adaptive limiter allowing at most limit calls within any period,
+	and fewer while the service is pushing back.
+*/
+func NewAdaptive(limit uint, period time.Duration, xOptions ...Option) (p *TAdaptive, err error) {
+	if err = validate(limit, period); nil != err {
+		return
+	}
+	p = &TAdaptive{
+		ceiling:	limit,
+		period:		period,
+		clock:		applyOptions(xOptions).clock,
+		effective:	float64(limit),
+	}
+	return
+}
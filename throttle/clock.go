@@ -0,0 +1,72 @@
+package throttle
+
+import (
+	"time"
+)
+
+/*	A Clock supplies the current time and timer channels to a Limiter.
+	The default is the system clock; tests may substitute a fake (see package throttletest)
+	with WithClock() so that sliding-window behaviour can be verified without real sleeps.
+*/
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+/*	A TimerClock is a Clock whose timers can be stopped early.  A Wait() that gives up, e.g. because its
+	context was canceled, stops its timer, so that a fake clock no longer counts it as pending.
+*/
+type TimerClock interface {
+	Clock
+	Timer(d time.Duration) (c <-chan time.Time, stop func())
+}
+
+var _ TimerClock = tSystemClock{}
+
+type tSystemClock struct{}
+
+func (tSystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (tSystemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (tSystemClock) Timer(d time.Duration) (<-chan time.Time, func()) {
+	pTimer := time.NewTimer(d)
+	return pTimer.C, func() {
+		pTimer.Stop()
+	}
+}
+
+type tOptions struct {
+	clock		Clock
+	observer	Observer
+}
+
+//	An Option configures a Limiter created by one of this package's constructors.
+type Option func(*tOptions)
+
+//	WithClock makes the Limiter read the time from c instead of the system clock.
+func WithClock(c Clock) Option {
+	return func(p *tOptions) {
+		p.clock = c
+	}
+}
+
+func applyOptions(xOptions []Option) (options tOptions) {
+	options.clock = tSystemClock{}
+	for _, option := range xOptions {
+		option(&options)
+	}
+	return
+}
+
+//	timer returns a channel that receives once d has passed on c, and a function that stops it if c can.
+func timer(c Clock, d time.Duration) (<-chan time.Time, func()) {
+	if timerClock, ok := c.(TimerClock); ok {
+		return timerClock.Timer(d)
+	}
+	return c.After(d), func() {}
+}
@@ -0,0 +1,112 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+//	TWaited reports how long each constraint made a Concurrency.Acquire() caller wait.
+type TWaited struct {
+	Concurrency	time.Duration	//	waiting for one of the in-flight slots to be released
+	Rate		time.Duration	//	waiting on the Limiter, if any
+}
+
+/*	A Concurrency is a semaphore capping the number of calls in flight at once.
+	It may be composed with a Limiter, so that a single Acquire() enforces both "N per period"
+	and "M in flight".  Every successful Acquire() must be matched by a Release() once the call is done.
+	Create one with NewConcurrency().  A zero-value Concurrency returns ErrNotInitialized from Acquire().
+*/
+type Concurrency struct {
+	chSlots	chan struct{}
+	limiter	Limiter
+	clock	Clock		//	measures TWaited
+}
+
+/*	Acquire blocks until a call may be made: first until fewer than the maximum are in flight, then
+	until the Limiter (if any) allows it.  The in-flight slot is claimed first so that no rate slot is
+	spent while still queued behind other calls.
+	If ctx is done first, nothing is held and ctx.Err() (or the Limiter's error) is returned.
+*/
+func (p *Concurrency) Acquire(ctx context.Context) (waited TWaited, err error) {
+	if nil == p.chSlots {
+		err = ErrNotInitialized
+		return
+	}
+
+	clock := p.getClock()
+	start := clock.Now()
+	select {
+	case p.chSlots <- struct{}{}:
+	case <-ctx.Done():
+		err = ctx.Err()
+		return
+	}
+	waited.Concurrency = clock.Now().Sub(start)
+
+	if nil != p.limiter {
+		start = clock.Now()
+		err = p.limiter.Wait(ctx)
+		waited.Rate = clock.Now().Sub(start)
+		if nil != err {
+			<-p.chSlots	//	give the in-flight slot back
+		}
+	}
+	return
+}
+
+/*	TryAcquire claims an in-flight slot and a rate slot only if both are available right now,
+	and reports whether it did.
+*/
+func (p *Concurrency) TryAcquire() (ok bool) {
+	if nil == p.chSlots {
+		return
+	}
+
+	select {
+	case p.chSlots <- struct{}{}:
+	default:
+		return
+	}
+
+	if ok = nil == p.limiter || p.limiter.TryAcquire(); !ok {
+		<-p.chSlots
+	}
+	return
+}
+
+//	Release frees the in-flight slot claimed by Acquire() or TryAcquire().
+func (p *Concurrency) Release() {
+	select {
+	case <-p.chSlots:
+	default:
+		panic(`throttle: Concurrency.Release() without matching Acquire()`)
+	}
+}
+
+//	InFlight returns the number of slots currently held.
+func (p *Concurrency) InFlight() int {
+	return len(p.chSlots)
+}
+
+func (p *Concurrency) getClock() (c Clock) {
+	if c = p.clock; nil == c {
+		c = tSystemClock{}
+	}
+	return
+}
+
+//\\//	functions
+
+/*	NewConcurrency returns a semaphore allowing at most maxInFlight calls at once, additionally
+	subject to limiter if it is not nil.  WithClock() sets the clock on which TWaited is measured,
+	which should be the limiter's.
+*/
+func NewConcurrency(maxInFlight uint, limiter Limiter, xOptions ...Option) (p *Concurrency, err error) {
+	if 0 == maxInFlight {
+		err = errors.New(`Concurrency maximum must be greater than zero`)
+	} else {
+		p = &Concurrency{chSlots: make(chan struct{}, maxInFlight), limiter: limiter, clock: applyOptions(xOptions).clock}
+	}
+	return
+}
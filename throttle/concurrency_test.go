@@ -0,0 +1,102 @@
+package throttle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/imtlab/pkg/throttle"
+	"github.com/imtlab/pkg/throttle/throttletest"
+)
+
+func TestConcurrency(t *testing.T) {
+	p, err := throttle.NewConcurrency(2, nil)
+	if nil != err {
+		t.Fatalf(`NewConcurrency() returned error: %v`, err)
+	}
+
+	if !p.TryAcquire() || !p.TryAcquire() || p.TryAcquire() {
+		t.Fatal(`TryAcquire() did not allow exactly 2 in flight`)
+	}
+	if 2 != p.InFlight() {
+		t.Fatalf(`InFlight() = %d; want 2`, p.InFlight())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = p.Acquire(ctx); context.Canceled != err {
+		t.Fatalf(`Acquire() with none free returned %v; want context.Canceled`, err)
+	}
+
+	p.Release()
+	if _, err = p.Acquire(context.Background()); nil != err {
+		t.Fatalf(`Acquire() after Release() returned %v`, err)
+	}
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	clock := throttletest.NewFakeClock(kStart)
+	pThrottle, _ := throttle.New(1, time.Second, throttle.WithClock(clock))
+	p, _ := throttle.NewConcurrency(2, pThrottle)
+
+	if !p.TryAcquire() {
+		t.Fatal(`TryAcquire() failed with both limits free`)
+	}
+	//	an in-flight slot is free but the rate slot is not, so nothing is held
+	if p.TryAcquire() || 1 != p.InFlight() {
+		t.Fatalf(`TryAcquire() over the rate limit left InFlight() = %d; want 1`, p.InFlight())
+	}
+}
+
+func TestConcurrencyWaited(t *testing.T) {
+	clock := throttletest.NewFakeClock(kStart)
+	pThrottle, _ := throttle.New(1, time.Second, throttle.WithClock(clock))
+	p, _ := throttle.NewConcurrency(1, pThrottle, throttle.WithClock(clock))
+	pThrottle.TryAcquire()
+
+	type tResult struct {
+		waited	throttle.TWaited
+		err		error
+	}
+	chResult := make(chan tResult)
+	go func() {
+		waited, err := p.Acquire(context.Background())
+		chResult <- tResult{waited, err}
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	result := <-chResult
+	if nil != result.err {
+		t.Fatalf(`Acquire() returned %v`, result.err)
+	}
+	if 0 != result.waited.Concurrency || time.Second != result.waited.Rate {
+		t.Fatalf(`waited = %+v; want no Concurrency and 1s Rate`, result.waited)
+	}
+
+	//	a failed rate wait gives the in-flight slot back
+	p.Release()
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		clock.BlockUntil(1)
+		cancel()
+	}()
+	if _, err := p.Acquire(ctx); context.Canceled != err {
+		t.Fatalf(`Acquire() returned %v; want context.Canceled`, err)
+	}
+	if 0 != p.InFlight() {
+		t.Fatalf(`InFlight() = %d after a failed Acquire(); want 0`, p.InFlight())
+	}
+}
+
+func TestConcurrencyZeroValue(t *testing.T) {
+	var p throttle.Concurrency
+
+	if _, err := p.Acquire(context.Background()); !errors.Is(err, throttle.ErrNotInitialized) {
+		t.Fatalf(`Acquire() returned %v; want ErrNotInitialized`, err)
+	}
+	if p.TryAcquire() || 0 != p.InFlight() {
+		t.Fatal(`a zero-value Concurrency granted a slot`)
+	}
+}
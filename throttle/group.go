@@ -0,0 +1,121 @@
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type tGroupEntry struct {
+	pThrottle	*TThrottle
+	lastUsed	time.Time
+}
+
+/*	A Group is a registry of throttles sharing one configuration, one per key (e.g. per customer or
+	per downstream host).  Each key's throttle is created on first use, and discarded once it has
+	been idle for the group's ttl and its window is empty.
+	Create one with NewGroup().  The zero value hands out uninitialized throttles, whose methods
+	return ErrNotInitialized.
+*/
+type Group struct {
+	sync.Mutex
+	limit		uint
+	period		time.Duration
+	ttl			time.Duration
+	xOptions	[]Option
+	clock		Clock
+	mEntries	map[string]*tGroupEntry
+	lastSweep	time.Time
+}
+
+/*	Get returns the throttle for key, creating it if necessary.
+	Callers should not hold on to it across idle periods, since an evicted throttle is replaced
+	by a fresh one on the next Get().
+*/
+func (p *Group) Get(key string) (pThrottle *TThrottle) {
+	p.Lock()
+	defer p.Unlock()
+
+	if nil == p.mEntries {	//	not created by NewGroup()
+		return new(TThrottle)
+	}
+
+	now := p.clock.Now()
+	p.sweep(now)
+
+	pEntry, ok := p.mEntries[key]
+	if !ok {
+		pEntry = new(tGroupEntry)
+		//	New() can't fail here because NewGroup() already validated the same arguments
+		pEntry.pThrottle, _ = New(p.limit, p.period, p.xOptions...)
+		p.mEntries[key] = pEntry
+	}
+	pEntry.lastUsed = now
+
+	return pEntry.pThrottle
+}
+
+//	Wait is TThrottle.Wait on the throttle for key.
+func (p *Group) Wait(ctx context.Context, key string) error {
+	return p.Get(key).Wait(ctx)
+}
+
+//	WaitN is TThrottle.WaitN on the throttle for key.
+func (p *Group) WaitN(ctx context.Context, key string, n uint) error {
+	return p.Get(key).WaitN(ctx, n)
+}
+
+//	TryAcquire is TThrottle.TryAcquire on the throttle for key.
+func (p *Group) TryAcquire(key string) bool {
+	return p.Get(key).TryAcquire()
+}
+
+//	Len returns the number of keys currently holding a throttle.
+func (p *Group) Len() int {
+	p.Lock()
+	defer p.Unlock()
+	return len(p.mEntries)
+}
+
+/*	sweep evicts idle keys, but at most once per ttl so that its cost is amortized.
+	The caller must hold the lock.
+*/
+func (p *Group) sweep(now time.Time) {
+	if now.Sub(p.lastSweep) < p.ttl {
+		return
+	}
+	p.lastSweep = now
+
+	for key, pEntry := range p.mEntries {
+		if p.ttl <= now.Sub(pEntry.lastUsed) && pEntry.pThrottle.idle(now) {
+			delete(p.mEntries, key)
+		}
+	}
+}
+
+/*	NewGroup returns a Group whose throttles each allow at most limit calls within any period,
+	and are evicted after ttl without use.  xOptions are applied to every throttle in the group,
+	and WithClock() also governs the group's idle tracking.
+*/
+func NewGroup(limit uint, period time.Duration, ttl time.Duration, xOptions ...Option) (p *Group, err error) {
+	//	validate the configuration once, up front, and pick up any clock option
+	var pPrototype *TThrottle
+	if pPrototype, err = New(limit, period, xOptions...); nil != err {
+		return
+	}
+	if 0 >= ttl {
+		err = fmt.Errorf(`Group ttl must be greater than zero: %v`, ttl)
+		return
+	}
+
+	p = &Group{
+		limit:		limit,
+		period:		period,
+		ttl:		ttl,
+		xOptions:	xOptions,
+		clock:		pPrototype.getClock(),
+		mEntries:	make(map[string]*tGroupEntry),
+	}
+	return
+}
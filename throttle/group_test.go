@@ -0,0 +1,66 @@
+package throttle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/imtlab/pkg/throttle"
+	"github.com/imtlab/pkg/throttle/throttletest"
+)
+
+func TestGroup(t *testing.T) {
+	clock := throttletest.NewFakeClock(kStart)
+	p, err := throttle.NewGroup(1, time.Second, time.Minute, throttle.WithClock(clock))
+	if nil != err {
+		t.Fatalf(`NewGroup() returned error: %v`, err)
+	}
+
+	if !p.TryAcquire(`a`) || !p.TryAcquire(`b`) {
+		t.Fatal(`TryAcquire() failed for a fresh key`)
+	}
+	if p.TryAcquire(`a`) {
+		t.Fatal(`TryAcquire() succeeded twice within the period for the same key`)
+	}
+	if pA := p.Get(`a`); pA != p.Get(`a`) {
+		t.Fatal(`Get() returned different throttles for the same key`)
+	}
+	if 2 != p.Len() {
+		t.Fatalf(`Len() = %d; want 2`, p.Len())
+	}
+}
+
+func TestGroupEviction(t *testing.T) {
+	clock := throttletest.NewFakeClock(kStart)
+	p, _ := throttle.NewGroup(1, time.Hour, time.Minute, throttle.WithClock(clock))
+
+	pA := p.Get(`a`)
+	pA.TryAcquire()
+	p.TryAcquire(`b`)
+
+	//	both have been idle for the ttl, but their windows are not yet empty
+	clock.Advance(2 * time.Minute)
+	p.Get(`c`)
+	if 3 != p.Len() {
+		t.Fatalf(`Len() = %d; want 3 while windows are still open`, p.Len())
+	}
+
+	//	now their windows are empty too, so the next use of the group evicts them
+	clock.Advance(time.Hour)
+	p.TryAcquire(`c`)
+	p.Get(`d`)
+	if 2 != p.Len() {
+		t.Fatalf(`Len() = %d; want 2 after eviction`, p.Len())
+	}
+	if p.Get(`a`) == pA {
+		t.Fatal(`Get() returned an evicted throttle`)
+	}
+}
+
+func TestGroupZeroValue(t *testing.T) {
+	var p throttle.Group
+	if err := p.Wait(context.Background(), `a`); !errors.Is(err, throttle.ErrNotInitialized) {
+		t.Fatalf(`Wait() returned %v; want ErrNotInitialized`, err)
+	}
+}
@@ -0,0 +1,158 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	//	ErrUnknownLane is returned by Lanes.Wait() for a lane that was not configured.
+	ErrUnknownLane	= errors.New(`Unknown throttle lane`)
+)
+
+type tLaneWaiter struct {
+	chReady		chan struct{}	//	closed when it is this waiter's turn
+	bDequeued	bool			//	guarded by the Lanes lock
+}
+
+type tLane struct {
+	name		string
+	weight		int
+	current		int				//	smooth weighted round-robin credit
+	xWaiters	[]*tLaneWaiter	//	FIFO within the lane
+}
+
+/*	Lanes queues callers of a Limiter in named lanes (e.g. "interactive" and "backfill"), so that when
+	the limiter runs short of capacity, waiters in higher-weighted lanes are served first.
+	Lanes share the limiter by weight rather than by strict priority (smooth weighted round-robin), so a
+	lane with weight 1 alongside one with weight 9 still gets every tenth slot and cannot starve.
+	Waiters are admitted to the limiter one at a time, in that order; callers using the limiter directly
+	bypass the lanes altogether.
+	Create one with NewLanes().
+*/
+type Lanes struct {
+	sync.Mutex
+	limiter	Limiter
+	mLanes	map[string]*tLane
+	xLanes	[]*tLane	//	the same lanes, in a stable order
+	bBusy	bool		//	whether some waiter currently holds the turn
+}
+
+/*	Wait blocks until the caller's turn comes up in lane and the limiter then grants it a slot,
+	or until ctx is done.
+*/
+func (p *Lanes) Wait(ctx context.Context, lane string) (err error) {
+	if err = ctx.Err(); nil != err {
+		return
+	}
+
+	p.Lock()
+	pLane, ok := p.mLanes[lane]
+	if !ok {
+		p.Unlock()
+		return fmt.Errorf(`%w: "%s"`, ErrUnknownLane, lane)
+	}
+
+	if p.bBusy {
+		pWaiter := &tLaneWaiter{chReady: make(chan struct{})}
+		pLane.xWaiters = append(pLane.xWaiters, pWaiter)
+		p.Unlock()
+
+		select {
+		case <-pWaiter.chReady:
+		case <-ctx.Done():
+			p.Lock()
+			if pWaiter.bDequeued {
+				//	the turn was handed to us just as ctx was done, so hand it on
+				p.next()
+			} else {
+				pLane.remove(pWaiter)
+			}
+			p.Unlock()
+			return ctx.Err()
+		}
+	} else {
+		p.bBusy = true
+		p.Unlock()
+	}
+
+	//	it's our turn
+	err = p.limiter.Wait(ctx)
+
+	p.Lock()
+	p.next()
+	p.Unlock()
+	return
+}
+
+/*	next hands the turn to the next waiter chosen by smooth weighted round-robin among the lanes that
+	have waiters, or marks the Lanes idle if there are none.  The caller must hold the lock.
+*/
+func (p *Lanes) next() {
+	var (
+		pChosen	*tLane
+		total	int
+	)
+	for _, pLane := range p.xLanes {
+		if 0 == len(pLane.xWaiters) {
+			continue
+		}
+		pLane.current += pLane.weight
+		total += pLane.weight
+		if nil == pChosen || pLane.current > pChosen.current {
+			pChosen = pLane
+		}
+	}
+
+	if nil == pChosen {
+		p.bBusy = false
+		return
+	}
+	pChosen.current -= total
+
+	pWaiter := pChosen.xWaiters[0]
+	pChosen.xWaiters = pChosen.xWaiters[1:]
+	pWaiter.bDequeued = true
+	close(pWaiter.chReady)
+}
+
+//	The caller must hold the Lanes lock.
+func (p *tLane) remove(pWaiter *tLaneWaiter) {
+	for i, pW := range p.xWaiters {
+		if pW == pWaiter {
+			p.xWaiters = append(p.xWaiters[:i], p.xWaiters[i+1:]...)
+			break
+		}
+	}
+}
+
+//\\//	functions
+
+/*	NewLanes returns Lanes admitting callers to limiter according to mWeights, which maps each
+	lane's name to its (positive) share of the limiter.
+*/
+func NewLanes(limiter Limiter, mWeights map[string]uint) (p *Lanes, err error) {
+	if nil == limiter {
+		return nil, errors.New(`Lanes require a Limiter`)
+	}
+	if 0 == len(mWeights) {
+		return nil, errors.New(`Lanes require at least one lane`)
+	}
+
+	p = &Lanes{limiter: limiter, mLanes: make(map[string]*tLane, len(mWeights))}
+	for name, weight := range mWeights {
+		if 0 == weight {
+			return nil, fmt.Errorf(`Lane "%s" must have a weight greater than zero`, name)
+		}
+		pLane := &tLane{name: name, weight: int(weight)}
+		p.mLanes[name] = pLane
+		p.xLanes = append(p.xLanes, pLane)
+	}
+	sort.Slice(p.xLanes, func(i, j int) bool {
+		return p.xLanes[i].name < p.xLanes[j].name
+	})
+	return
+}
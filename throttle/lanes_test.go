@@ -0,0 +1,60 @@
+package throttle_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/imtlab/pkg/throttle"
+)
+
+func TestLanes(t *testing.T) {
+	//	the bubble's fake time drives the throttle, and synctest.Wait() tells when a waiter has queued
+	synctest.Test(t, func(t *testing.T) {
+		pThrottle, _ := throttle.New(1, time.Second)
+		p, err := throttle.NewLanes(pThrottle, map[string]uint{`bulk`: 1, `interactive`: 3})
+		if nil != err {
+			t.Fatalf(`NewLanes() returned error: %v`, err)
+		}
+		if err = p.Wait(context.Background(), `other`); !errors.Is(err, throttle.ErrUnknownLane) {
+			t.Fatalf(`Wait() on an unknown lane returned %v; want ErrUnknownLane`, err)
+		}
+
+		var (
+			mutex	sync.Mutex
+			xOrder	[]string
+		)
+		wait := func(lane string) {
+			if err := p.Wait(context.Background(), lane); nil != err {
+				t.Errorf(`Wait(%s) returned %v`, lane, err)
+			}
+			mutex.Lock()
+			xOrder = append(xOrder, lane)
+			mutex.Unlock()
+		}
+
+		//	with the only slot taken, the first waiter holds the turn until the next opens, and the rest
+		//	queue up behind it
+		pThrottle.TryAcquire()
+		go wait(`bulk`)
+		synctest.Wait()
+		for i := 0; i < 3; i++ {
+			go wait(`bulk`)
+			go wait(`interactive`)
+			synctest.Wait()
+		}
+		time.Sleep(time.Minute)
+		synctest.Wait()
+
+		//	interactive gets three turns to bulk's one until it runs out of waiters
+		xWant := []string{`bulk`, `interactive`, `bulk`, `interactive`, `interactive`, `bulk`, `bulk`}
+		for i := range xWant {
+			if xWant[i] != xOrder[i] {
+				t.Fatalf(`served %v; want %v`, xOrder, xWant)
+			}
+		}
+	})
+}
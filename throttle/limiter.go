@@ -0,0 +1,70 @@
+package throttle
+
+import (
+	"context"
+	"time"
+)
+
+/*	A Limiter limits the rate of calls.  TThrottle (a sliding log), TTokenBucket, TFixedWindow
+	and TSlidingWindow all implement it, so callers can switch algorithms without code changes.
+	n is the number of slots (or tokens) a call costs; the methods without N cost one.
+*/
+type Limiter interface {
+	Wait(ctx context.Context) error
+	WaitN(ctx context.Context, n uint) error
+	TryAcquire() bool
+	TryAcquireN(n uint) bool
+	Reserve() *Reservation
+	ReserveN(n uint) *Reservation
+}
+
+/*	tAlgorithm is implemented by the counter-based limiters, whose exported methods are all
+	expressed in terms of reserve() by the helper functions below.
+*/
+type tAlgorithm interface {
+	Lock()
+	Unlock()
+	getClock() Clock
+	checkN(n uint) error
+	//	reserve commits n slots and returns the instant they may be used, plus a function that
+	//	gives them back.  Both it and the returned function are called with the lock held.
+	reserve(now time.Time, n uint) (instant time.Time, undo func())
+}
+
+func tryAcquireN(p tAlgorithm, n uint) (ok bool) {
+	if nil != p.checkN(n) {
+		return
+	}
+	p.Lock()
+	now := p.getClock().Now()	//	latch this instant
+
+	instant, undo := p.reserve(now, n)
+	if ok = !instant.After(now); !ok {
+		undo()
+	}
+
+	p.Unlock()
+	return
+}
+
+func reserveN(p tAlgorithm, n uint) (pReservation *Reservation) {
+	pReservation = &Reservation{locker: p, clock: p.getClock()}
+	if nil != p.checkN(n) {
+		return
+	}
+	p.Lock()
+	pReservation.grant(p.reserve(pReservation.clock.Now(), n))
+	p.Unlock()
+	return
+}
+
+func waitN(ctx context.Context, p tAlgorithm, n uint) (err error) {
+	if err = p.checkN(n); nil != err {
+		return
+	}
+	if err = ctx.Err(); nil != err {
+		return
+	}
+
+	return reserveN(p, n).wait(ctx)
+}
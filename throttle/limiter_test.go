@@ -0,0 +1,152 @@
+package throttle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/imtlab/pkg/throttle"
+	"github.com/imtlab/pkg/throttle/throttletest"
+)
+
+//	delays reserves count slots one at a time, without the clock moving, and returns their delays.
+func delays(p throttle.Limiter, count int) (xDelays []time.Duration) {
+	for i := 0; i < count; i++ {
+		xDelays = append(xDelays, p.Reserve().Delay())
+	}
+	return
+}
+
+func TestLimiters(t *testing.T) {
+	xCases := []struct {
+		name	string
+		new		func(throttle.Clock) (throttle.Limiter, error)
+		xWant	[]time.Duration
+	}{
+		{
+			name:	`sliding log`,
+			new:	func(c throttle.Clock) (throttle.Limiter, error) { return throttle.New(2, time.Second, throttle.WithClock(c)) },
+			xWant:	[]time.Duration{0, 0, 1000 * time.Millisecond, 1000 * time.Millisecond, 2000 * time.Millisecond},
+		},
+		{
+			name:	`token bucket`,
+			new:	func(c throttle.Clock) (throttle.Limiter, error) { return throttle.NewTokenBucket(2, time.Second, 2, throttle.WithClock(c)) },
+			xWant:	[]time.Duration{0, 0, 500 * time.Millisecond, 1000 * time.Millisecond, 1500 * time.Millisecond},
+		},
+		{
+			name:	`fixed window`,
+			new:	func(c throttle.Clock) (throttle.Limiter, error) { return throttle.NewFixedWindow(2, time.Second, throttle.WithClock(c)) },
+			xWant:	[]time.Duration{0, 0, 1000 * time.Millisecond, 1000 * time.Millisecond, 2000 * time.Millisecond},
+		},
+	}
+
+	for _, c := range xCases {
+		t.Run(c.name, func(t *testing.T) {
+			clock := throttletest.NewFakeClock(kStart)
+			p, err := c.new(clock)
+			if nil != err {
+				t.Fatalf(`constructor returned error: %v`, err)
+			}
+
+			xGot := delays(p, len(c.xWant))
+			for i := range c.xWant {
+				if c.xWant[i] != xGot[i] {
+					t.Fatalf(`delays = %v; want %v`, xGot, c.xWant)
+				}
+			}
+
+			if _, ok := p.(*throttle.TThrottle); !ok {	//	TThrottle's errors are covered by its own tests
+				if err = p.WaitN(context.Background(), 3); !errors.Is(err, throttle.ErrExceedsLimit) {
+					t.Fatalf(`WaitN(3) returned %v; want ErrExceedsLimit`, err)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	clock := throttletest.NewFakeClock(kStart)
+	p, _ := throttle.NewTokenBucket(4, time.Second, 2, throttle.WithClock(clock))
+
+	if !p.TryAcquireN(2) || p.TryAcquire() {
+		t.Fatal(`the bucket did not start out holding exactly burst tokens`)
+	}
+	clock.Advance(250 * time.Millisecond)
+	if !p.TryAcquire() || p.TryAcquire() {
+		t.Fatal(`the bucket did not refill one token in 250ms`)
+	}
+
+	//	it never holds more than burst, however long it is left
+	clock.Advance(time.Hour)
+	if !p.TryAcquireN(2) || p.TryAcquire() {
+		t.Fatal(`the bucket refilled beyond burst`)
+	}
+}
+
+func TestFixedWindowBoundary(t *testing.T) {
+	clock := throttletest.NewFakeClock(kStart.Add(900 * time.Millisecond))
+	p, _ := throttle.NewFixedWindow(2, time.Second, throttle.WithClock(clock))
+
+	p.TryAcquireN(2)
+	if dur := p.Reserve().Delay(); 100 * time.Millisecond != dur {
+		t.Fatalf(`Reserve().Delay() = %v; want 100ms, until the next window`, dur)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	clock := throttletest.NewFakeClock(kStart)
+	p, _ := throttle.NewSlidingWindow(4, time.Second, throttle.WithClock(clock))
+
+	if !p.TryAcquireN(4) || p.TryAcquire() {
+		t.Fatal(`the first window did not allow exactly its limit`)
+	}
+
+	//	a quarter of the way into the next window, 3/4 of the previous 4 still count, leaving room for 1
+	clock.Advance(1250 * time.Millisecond)
+	if !p.TryAcquire() || p.TryAcquire() {
+		t.Fatal(`the weighted previous window left other than one slot`)
+	}
+
+	//	a reservation waits until enough of the previous window has slid out
+	if dur := p.Reserve().Delay(); 250 * time.Millisecond != dur {
+		t.Fatalf(`Reserve().Delay() = %v; want 250ms`, dur)
+	}
+}
+
+func TestAdaptive(t *testing.T) {
+	clock := throttletest.NewFakeClock(kStart)
+	p, err := throttle.NewAdaptive(8, time.Second, throttle.WithClock(clock))
+	if nil != err {
+		t.Fatalf(`NewAdaptive() returned error: %v`, err)
+	}
+
+	p.Penalize(0)
+	if 4 != p.Limit() {
+		t.Fatalf(`Limit() after Penalize() = %d; want 4`, p.Limit())
+	}
+	if !p.TryAcquireN(4) || p.TryAcquire() {
+		t.Fatal(`the penalized limiter did not allow exactly its effective limit`)
+	}
+
+	//	additive increase: about one more slot per limit's worth of successes
+	for i := 0; i < 5; i++ {
+		p.Succeed()
+	}
+	if 5 != p.Limit() {
+		t.Fatalf(`Limit() after 5 Succeed()s = %d; want 5`, p.Limit())
+	}
+	for i := 0; i < 100; i++ {
+		p.Succeed()
+	}
+	if 8 != p.Limit() {
+		t.Fatalf(`Limit() = %d; want the ceiling of 8`, p.Limit())
+	}
+
+	//	Retry-After blocks everything until it has passed
+	clock.Advance(time.Minute)
+	p.Penalize(2 * time.Second)
+	if dur := p.Reserve().Delay(); 2 * time.Second != dur {
+		t.Fatalf(`Reserve().Delay() after Penalize(2s) = %v; want 2s`, dur)
+	}
+}
@@ -0,0 +1,98 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/*	A Reservation holds slots claimed from a Limiter by Reserve() or ReserveN().
+	It is modelled after golang.org/x/time/rate.Reservation.
+*/
+type Reservation struct {
+	locker		sync.Locker		//	the lock of the Limiter that issued the reservation
+	clock		Clock
+	ok			bool
+	canceled	bool			//	guarded by locker
+	instant		time.Time		//	the instant at which the slots may be used
+	undo		func()			//	gives the slots back; called with locker held
+}
+
+//	OK reports whether the slots were actually reserved.
+func (p *Reservation) OK() bool {
+	return p.ok
+}
+
+/*	Delay returns how long the holder must wait before using the slots.
+	Zero means they may be used immediately.
+*/
+func (p *Reservation) Delay() (dur time.Duration) {
+	if p.ok {
+		if now := p.clock.Now(); p.instant.After(now) {
+			dur = p.instant.Sub(now)
+		}
+	}
+	return
+}
+
+/*	Cancel gives the slots back to the Limiter so that they may be claimed by other callers.
+	It has no effect if the reservation is not OK(), was already canceled, or its instant has already passed.
+*/
+func (p *Reservation) Cancel() {
+	if !p.ok {
+		return
+	}
+	p.locker.Lock()
+	if !p.canceled && p.instant.After(p.clock.Now()) {
+		p.undo()
+		p.canceled = true
+	}
+	p.locker.Unlock()
+}
+
+//	release is Cancel() for slots known to have gone unused, which are given back even if their instant has passed.
+func (p *Reservation) release() {
+	if !p.ok {
+		return
+	}
+	p.locker.Lock()
+	if !p.canceled {
+		p.undo()
+		p.canceled = true
+	}
+	p.locker.Unlock()
+}
+
+//	grant marks the reservation OK.  The issuing Limiter calls it with its lock held.
+func (p *Reservation) grant(instant time.Time, undo func()) {
+	p.instant, p.undo, p.ok = instant, undo, true
+}
+
+/*	wait sleeps until the reservation's instant or until ctx is done, whichever comes first,
+	canceling the reservation in the latter case.
+	If ctx carries a deadline that would expire before the instant, wait cancels the reservation and
+	returns context.DeadlineExceeded immediately rather than sleeping in vain.
+*/
+func (p *Reservation) wait(ctx context.Context) (err error) {
+	var dur time.Duration
+	if dur = p.Delay(); 0 == dur {
+		return	//	no waiting necessary
+	}
+
+	//	measure the deadline on the same clock as the delay, which may be a fake one
+	if deadline, ok := ctx.Deadline(); ok && deadline.Sub(p.clock.Now()) < dur {
+		p.Cancel()
+		return context.DeadlineExceeded
+	}
+
+	chTimer, stop := timer(p.clock, dur)
+	defer stop()
+	select {
+	case <-chTimer:
+	case <-ctx.Done():
+		p.Cancel()
+		err = ctx.Err()
+	}
+
+	return
+}
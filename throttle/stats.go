@@ -0,0 +1,73 @@
+package throttle
+
+import (
+	"time"
+)
+
+//	TStats is a snapshot of how much a TThrottle has been limiting its callers.
+type TStats struct {
+	InWindow	uint			//	slots reserved within the last period (including any reserved for the future)
+	Acquired	uint64			//	total slots ever reserved, including those later canceled
+	Canceled	uint64			//	total slots given back by Reservation.Cancel() or an abandoned Wait()
+	TotalWait	time.Duration	//	total time callers were told to wait
+	MaxWait		time.Duration	//	the longest any caller was told to wait
+}
+
+/*	An Observer is notified of each acquisition and cancellation, so that the counters in TStats
+	can be exported as they change (e.g. to Prometheus or expvar).
+	Its methods are called with the throttle's lock held, so they must be quick and must not call
+	back into the throttle.
+*/
+type Observer interface {
+	Acquired(n uint, wait time.Duration)
+	Canceled(n uint)
+}
+
+//	WithObserver registers o to be notified of a TThrottle's acquisitions and cancellations.
+func WithObserver(o Observer) Option {
+	return func(p *tOptions) {
+		p.observer = o
+	}
+}
+
+//	Stats returns a snapshot of the throttle's counters.
+func (p *TThrottle) Stats() (stats TStats) {
+	p.Lock()
+	defer p.Unlock()
+
+	stats = p.stats
+	if nil == p.fifo {
+		return
+	}
+
+	//	count the instants within the last period; a channel can only be inspected by draining it
+	since := p.now().Add(-p.duration)
+	for i := len(p.fifo); 0 < i; i-- {
+		t := <-p.fifo
+		if t.After(since) {
+			stats.InWindow++
+		}
+		p.fifo <- t
+	}
+	return
+}
+
+//	The caller must hold the lock.
+func (p *TThrottle) acquired(n uint, wait time.Duration) {
+	p.stats.Acquired += uint64(n)
+	p.stats.TotalWait += wait
+	if wait > p.stats.MaxWait {
+		p.stats.MaxWait = wait
+	}
+	if nil != p.observer {
+		p.observer.Acquired(n, wait)
+	}
+}
+
+//	The caller must hold the lock.
+func (p *TThrottle) canceled(n uint) {
+	p.stats.Canceled += uint64(n)
+	if nil != p.observer {
+		p.observer.Canceled(n)
+	}
+}
@@ -0,0 +1,55 @@
+package throttle_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imtlab/pkg/throttle"
+	"github.com/imtlab/pkg/throttle/throttletest"
+)
+
+//	tObserver totals what it is notified of.
+type tObserver struct {
+	acquired	uint
+	waited		time.Duration
+	canceled	uint
+}
+
+func (p *tObserver) Acquired(n uint, wait time.Duration) {
+	p.acquired += n
+	p.waited += wait
+}
+
+func (p *tObserver) Canceled(n uint) {
+	p.canceled += n
+}
+
+func TestStats(t *testing.T) {
+	clock := throttletest.NewFakeClock(kStart)
+	pObserver := new(tObserver)
+	p, _ := throttle.New(2, time.Second, throttle.WithClock(clock), throttle.WithObserver(pObserver))
+
+	p.TryAcquire()
+	clock.Advance(500 * time.Millisecond)
+	p.TryAcquire()
+	p.TryAcquire()	//	fails, so counts for nothing
+	p.Reserve().Cancel()
+	if dur := p.Reserve().Delay(); 500 * time.Millisecond != dur {
+		t.Fatalf(`Reserve().Delay() = %v; want 500ms`, dur)
+	}
+
+	//	the log holds only the limit's worth of instants, 500ms and the reservation for 1s
+	want := throttle.TStats{InWindow: 2, Acquired: 4, Canceled: 1, TotalWait: time.Second, MaxWait: 500 * time.Millisecond}
+	if stats := p.Stats(); want != stats {
+		t.Fatalf(`Stats() = %+v; want %+v`, stats, want)
+	}
+	if 4 != pObserver.acquired || time.Second != pObserver.waited || 1 != pObserver.canceled {
+		t.Fatalf(`observer saw %+v; want 4 acquired, 1s waited and 1 canceled`, *pObserver)
+	}
+
+	//	by 1.5s, the call at 500ms has left the window, and only the reservation for 1s is still in it
+	clock.Advance(time.Second)
+	if stats := p.Stats(); 1 != stats.InWindow {
+		t.Fatalf(`Stats().InWindow = %d; want 1`, stats.InWindow)
+	}
+}
@@ -0,0 +1,219 @@
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+//\\//	type definitions (and attached methods)
+
+/*	A Store holds sliding logs (see TThrottle) on behalf of TDistributed limiters, so that limiters
+	in several goroutines or processes can coordinate through it.
+	Update must be atomic with respect to every other Update of the same key, including those made by
+	other processes sharing the store: it reads the log stored under key (nil if none), passes it to fn,
+	and stores whatever fn returns.  fn may be called with a slice it is free to modify.
+	Since the logs hold instants, everyone sharing a store must have reasonably synchronized clocks.
+*/
+type Store interface {
+	Update(ctx context.Context, key string, fn func(xInstants []time.Time) []time.Time) error
+}
+
+/*	A TMemoryStore is a Store that keeps its logs in memory, so it only coordinates limiters
+	within one process.  The zero value is ready to use.
+*/
+type TMemoryStore struct {
+	sync.Mutex
+	mLogs	map[string][]time.Time
+}
+
+func (p *TMemoryStore) Update(ctx context.Context, key string, fn func(xInstants []time.Time) []time.Time) (err error) {
+	if err = ctx.Err(); nil != err {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+
+	if nil == p.mLogs {
+		p.mLogs = make(map[string][]time.Time)
+	}
+	if xInstants := fn(p.mLogs[key]); 0 == len(xInstants) {
+		delete(p.mLogs, key)
+	} else {
+		p.mLogs[key] = xInstants
+	}
+	return
+}
+
+var _ Limiter = (*TDistributed)(nil)
+
+/*	A TDistributed is a sliding-log limiter like TThrottle, except that the log is kept in a Store
+	under a key, so every TDistributed using the same store and key shares one window.
+	Create one with NewDistributed().
+	Store errors surface from Wait() and WaitN(); TryAcquire() reports false and Reserve() returns
+	a reservation that is not OK().
+*/
+type TDistributed struct {
+	sync.Mutex		//	guards Reservation.Cancel() bookkeeping; the store provides the atomicity
+	store	Store
+	key		string
+	limit	uint
+	period	time.Duration
+	clock	Clock
+}
+
+func (p *TDistributed) Wait(ctx context.Context) error {
+	return p.WaitN(ctx, 1)
+}
+
+func (p *TDistributed) WaitN(ctx context.Context, n uint) (err error) {
+	if err = p.checkN(n); nil != err {
+		return
+	}
+	if err = ctx.Err(); nil != err {
+		return
+	}
+
+	var pReservation *Reservation
+	if pReservation, err = p.reserveN(ctx, n); nil == err {
+		err = pReservation.wait(ctx)
+	}
+	return
+}
+
+func (p *TDistributed) TryAcquire() bool {
+	return p.TryAcquireN(1)
+}
+
+func (p *TDistributed) TryAcquireN(n uint) (ok bool) {
+	if nil != p.checkN(n) {
+		return
+	}
+	now := p.clock.Now()	//	latch this instant
+
+	err := p.store.Update(context.Background(), p.key, func(xInstants []time.Time) []time.Time {
+		xOut, instant, _ := reserveLog(xInstants, now, n, p.limit, p.period)
+		if ok = !instant.After(now); ok {
+			return xOut
+		}
+		return xInstants
+	})
+	return ok && nil == err
+}
+
+func (p *TDistributed) Reserve() *Reservation {
+	return p.ReserveN(1)
+}
+
+func (p *TDistributed) ReserveN(n uint) (pReservation *Reservation) {
+	var err error
+	if pReservation, err = p.reserveN(context.Background(), n); nil != err {
+		pReservation = &Reservation{locker: p, clock: p.clock}
+	}
+	return
+}
+
+func (p *TDistributed) reserveN(ctx context.Context, n uint) (pReservation *Reservation, err error) {
+	if err = p.checkN(n); nil != err {
+		return
+	}
+	now := p.clock.Now()	//	latch this instant
+
+	var (
+		instant		time.Time
+		xEvicted	[]time.Time
+	)
+	err = p.store.Update(ctx, p.key, func(xInstants []time.Time) (xOut []time.Time) {
+		xOut, instant, xEvicted = reserveLog(xInstants, now, n, p.limit, p.period)
+		return
+	})
+	if nil != err {
+		return
+	}
+
+	pReservation = &Reservation{locker: p, clock: p.clock}
+	pReservation.grant(instant, func() {
+		//	best effort: if the store fails now, the slots simply go unused
+		p.store.Update(context.Background(), p.key, func(xInstants []time.Time) []time.Time {
+			return unreserveLog(xInstants, instant, n, xEvicted, p.limit)
+		})
+	})
+	return
+}
+
+func (p *TDistributed) checkN(n uint) (err error) {
+	if nil == p.store {	//	guard against pinheadedness
+		err = ErrNotInitialized
+	} else if n > p.limit {
+		err = fmt.Errorf(`%w: n = %d; limit = %d`, ErrExceedsLimit, n, p.limit)
+	}
+	return
+}
+
+//\\//	functions
+
+/*	NewDistributed returns a limiter allowing at most limit calls within any period, counted across
+	every limiter sharing store and key.
+*/
+func NewDistributed(store Store, key string, limit uint, period time.Duration, xOptions ...Option) (p *TDistributed, err error) {
+	if err = validate(limit, period); nil != err {
+		return
+	}
+	p = &TDistributed{
+		store:	store,
+		key:	key,
+		limit:	limit,
+		period:	period,
+		clock:	applyOptions(xOptions).clock,
+	}
+	return
+}
+
+/*	reserveLog is the sliding-log algorithm of TThrottle.reserve() applied to a slice holding at most
+	limit instants, oldest first.  It returns the updated log, the instant at which the n slots may be
+	used, and the instants evicted from the head to make room.
+*/
+func reserveLog(xInstants []time.Time, now time.Time, n uint, limit uint, period time.Duration) (xOut []time.Time, instant time.Time, xEvicted []time.Time) {
+	instant = now
+
+	if overflow := len(xInstants) + int(n) - int(limit); 0 < overflow {
+		xEvicted = append([]time.Time(nil), xInstants[:overflow]...)
+		xInstants = xInstants[overflow:]
+
+		//	the last instant evicted determines the instant of the next allowable call
+		if next := xEvicted[overflow - 1].Add(period); next.After(now) {
+			instant = next
+		}
+	}
+
+	xOut = make([]time.Time, 0, len(xInstants) + int(n))
+	xOut = append(xOut, xInstants...)
+	for i := uint(0); i < n; i++ {
+		xOut = append(xOut, instant)
+	}
+	return
+}
+
+//	unreserveLog is TThrottle.unreserve() applied to a slice; it undoes reserveLog().
+func unreserveLog(xInstants []time.Time, instant time.Time, n uint, xEvicted []time.Time, limit uint) (xOut []time.Time) {
+	xOut = make([]time.Time, 0, len(xEvicted) + len(xInstants))
+	xOut = append(xOut, xEvicted...)
+	xOut = append(xOut, xInstants...)
+
+	//	remove the most recent n occurrences of instant
+	for i := len(xOut) - 1; 0 <= i && 0 != n; i-- {
+		if xOut[i].Equal(instant) {
+			xOut = append(xOut[:i], xOut[i+1:]...)
+			n--
+		}
+	}
+
+	sortInstants(xOut)	//	as in TThrottle.unreserve()
+
+	//	drop the oldest instants should there somehow be more than will fit
+	if overflow := len(xOut) - int(limit); 0 < overflow {
+		xOut = xOut[overflow:]
+	}
+	return
+}
@@ -0,0 +1,110 @@
+//go:build unix
+
+package throttle
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+//	how often TFileStore retries a lock held by another process
+const kFileLockRetry = 5 * time.Millisecond
+
+/*	A TFileStore is a Store that keeps each key's log in its own file under a directory, using
+	advisory file locks (flock) to make Update atomic across every process on the host sharing
+	the directory.  It is a local stand-in for a shared network store.
+	Create one with NewFileStore().
+*/
+type TFileStore struct {
+	dir	string
+}
+
+func (p *TFileStore) Update(ctx context.Context, key string, fn func(xInstants []time.Time) []time.Time) (err error) {
+	var pFile *os.File
+	if pFile, err = os.OpenFile(p.path(key), os.O_RDWR|os.O_CREATE, 0666); nil != err {
+		return
+	}
+	defer pFile.Close()
+
+	if err = lockFile(ctx, pFile); nil != err {
+		return
+	}
+	//	closing the file releases the lock, but unlock explicitly in case the descriptor is shared
+	defer syscall.Flock(int(pFile.Fd()), syscall.LOCK_UN)
+
+	var xInstants []time.Time
+	if xInstants, err = readInstants(pFile); nil != err {
+		return fmt.Errorf(`Failed to read %s: %w`, pFile.Name(), err)
+	}
+
+	xInstants = fn(xInstants)
+
+	if err = writeInstants(pFile, xInstants); nil != err {
+		err = fmt.Errorf(`Failed to write %s: %w`, pFile.Name(), err)
+	}
+	return
+}
+
+//	path maps key onto a file name that is safe whatever characters key contains.
+func (p *TFileStore) path(key string) string {
+	return filepath.Join(p.dir, `throttle-` + base64.RawURLEncoding.EncodeToString([]byte(key)))
+}
+
+//\\//	functions
+
+//	NewFileStore returns a TFileStore keeping its files in dir, which is created if necessary.
+func NewFileStore(dir string) (p *TFileStore, err error) {
+	if err = os.MkdirAll(dir, os.ModePerm); nil == err {
+		p = &TFileStore{dir: dir}
+	}
+	return
+}
+
+/*	lockFile takes an exclusive lock on pFile, polling rather than blocking in flock() so that it
+	gives up when ctx is done.
+*/
+func lockFile(ctx context.Context, pFile *os.File) (err error) {
+	for {
+		if err = syscall.Flock(int(pFile.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); !errors.Is(err, syscall.EWOULDBLOCK) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(kFileLockRetry):
+		}
+	}
+}
+
+//	The file holds each instant as a big-endian int64 count of nanoseconds since the Unix epoch.
+func readInstants(pFile *os.File) (xInstants []time.Time, err error) {
+	var xBytes []byte
+	if xBytes, err = io.ReadAll(pFile); nil != err {
+		return
+	}
+	for 8 <= len(xBytes) {
+		xInstants = append(xInstants, time.Unix(0, int64(binary.BigEndian.Uint64(xBytes))))
+		xBytes = xBytes[8:]
+	}
+	return
+}
+
+func writeInstants(pFile *os.File, xInstants []time.Time) (err error) {
+	xBytes := make([]byte, 0, 8 * len(xInstants))
+	for _, t := range xInstants {
+		xBytes = binary.BigEndian.AppendUint64(xBytes, uint64(t.UnixNano()))
+	}
+
+	if err = pFile.Truncate(0); nil == err {
+		_, err = pFile.WriteAt(xBytes, 0)
+	}
+	return
+}
@@ -0,0 +1,52 @@
+//go:build unix
+
+package throttle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/imtlab/pkg/throttle"
+	"github.com/imtlab/pkg/throttle/throttletest"
+)
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	clock := throttletest.NewFakeClock(kStart)
+
+	//	two stores on one directory stand in for two processes
+	store, err := throttle.NewFileStore(dir)
+	if nil != err {
+		t.Fatalf(`NewFileStore() returned error: %v`, err)
+	}
+	store2, _ := throttle.NewFileStore(dir)
+	p, _ := throttle.NewDistributed(store, `a/b key`, 2, time.Second, throttle.WithClock(clock))
+	p2, _ := throttle.NewDistributed(store2, `a/b key`, 2, time.Second, throttle.WithClock(clock))
+
+	if !p.TryAcquire() {
+		t.Fatal(`TryAcquire() failed on an empty log`)
+	}
+	clock.Advance(100 * time.Millisecond)
+	if !p2.TryAcquire() || p.TryAcquire() {
+		t.Fatal(`the limiters did not share the limit of 2`)
+	}
+	//	the log survives the file round trip to the nanosecond
+	if dur := p2.Reserve().Delay(); 900 * time.Millisecond != dur {
+		t.Fatalf(`Reserve().Delay() = %v; want 900ms`, dur)
+	}
+
+	//	while one process holds the lock, the other waits for it no longer than its context allows
+	err = store.Update(context.Background(), `a/b key`, func(xInstants []time.Time) []time.Time {
+		ctx, cancel := context.WithTimeout(context.Background(), 20 * time.Millisecond)
+		defer cancel()
+		if err := p2.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf(`Wait() with the lock held elsewhere returned %v; want context.DeadlineExceeded`, err)
+		}
+		return xInstants
+	})
+	if nil != err {
+		t.Fatalf(`Update() returned error: %v`, err)
+	}
+}
@@ -0,0 +1,39 @@
+package throttle_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imtlab/pkg/throttle"
+	"github.com/imtlab/pkg/throttle/throttletest"
+)
+
+func TestDistributed(t *testing.T) {
+	clock := throttletest.NewFakeClock(kStart)
+	store := new(throttle.TMemoryStore)
+	p, err := throttle.NewDistributed(store, `key`, 2, time.Second, throttle.WithClock(clock))
+	if nil != err {
+		t.Fatalf(`NewDistributed() returned error: %v`, err)
+	}
+	//	a second limiter on the same store and key shares the log
+	p2, _ := throttle.NewDistributed(store, `key`, 2, time.Second, throttle.WithClock(clock))
+
+	if !p.TryAcquire() {
+		t.Fatal(`TryAcquire() failed on an empty log`)
+	}
+	clock.Advance(100 * time.Millisecond)
+	if !p2.TryAcquire() || p.TryAcquire() {
+		t.Fatal(`the limiters did not share the limit of 2`)
+	}
+
+	//	canceling out of order leaves the log as it was
+	a, b := p.Reserve(), p2.Reserve()
+	a.Cancel()
+	b.Cancel()
+	if dur := p.Reserve().Delay(); 900 * time.Millisecond != dur {
+		t.Fatalf(`Reserve().Delay() = %v; want 900ms`, dur)
+	}
+	if dur := p2.Reserve().Delay(); time.Second != dur {
+		t.Fatalf(`second Reserve().Delay() = %v; want 1s`, dur)
+	}
+}
@@ -4,45 +4,331 @@
 package throttle
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
 
+//\\//	package-scope constants and variables
+
+var (
+	//	ErrExceedsLimit is returned when more slots are requested at once than the throttle's limit allows.
+	ErrExceedsLimit		= errors.New(`Requested slots exceed the throttle limit`)
+	//	ErrNotInitialized is returned when a TThrottle is used without having been created by New() or Init()'ed.
+	ErrNotInitialized	= errors.New(`Throttle was not initialized; use New() or call Init()`)
+	//	ErrZeroLimit is returned when a throttle is created or reconfigured with a limit of zero.
+	ErrZeroLimit		= errors.New(`Throttle limit must be greater than zero`)
+	//	ErrInvalidPeriod is returned when a throttle is created or reconfigured with a period that is not positive.
+	ErrInvalidPeriod	= errors.New(`Throttle period must be greater than zero`)
+)
+
+//\\//	type definitions (and attached methods)
+
+var _ Limiter = (*TThrottle)(nil)
+
+/*	A TThrottle limits calls to at most limit within any period of duration by keeping a log of the
+	instants of the most recent limit calls (a sliding log).
+	Create one with New(), or declare one and call Init() on it before use.
+	A TThrottle that was never initialized returns ErrNotInitialized from its methods.
+*/
 type TThrottle struct {
 	sync.Mutex
 	limit		uint
 	duration	time.Duration
 	fifo		chan time.Time
+	clock		Clock		//	nil means the system clock
+	latest		time.Time	//	the most recent instant ever reserved
+	observer	Observer
+	stats		TStats		//	all but InWindow are maintained as we go
 }
 
-func (p *TThrottle) Init(numeratorLimit uint, denominatorSeconds uint) {
-	p.limit = numeratorLimit
-	p.duration = time.Second * time.Duration(denominatorSeconds)
-	p.fifo = make(chan time.Time, numeratorLimit)
+/*	Init prepares the throttle to allow numeratorLimit calls within any period of denominatorSeconds seconds.
+	It returns an error if either argument is zero, in which case the throttle remains unusable.
+	Use New() for periods that are not a whole number of seconds.
+*/
+func (p *TThrottle) Init(numeratorLimit uint, denominatorSeconds uint) error {
+	p.Lock()
+	defer p.Unlock()
+	return p.init(numeratorLimit, time.Second * time.Duration(denominatorSeconds))
 }
 
+/*	SetLimit changes the throttle to allow limit calls within any period, without losing track of the
+	calls already made: the most recent instants (up to the new limit) are carried over into the new
+	window.  It is safe to call while other goroutines are using the throttle, e.g. on a config reload.
+	Reservations already granted keep their instants.
+	SetLimit also initializes a throttle that was never initialized.
+*/
+func (p *TThrottle) SetLimit(limit uint, period time.Duration) (err error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if nil == p.fifo {
+		return p.init(limit, period)
+	}
+	if err = validate(limit, period); nil != err {
+		return
+	}
+
+	fifo := make(chan time.Time, limit)
+	for overflow := len(p.fifo) - int(limit); 0 < overflow; overflow-- {
+		<-p.fifo	//	discard the oldest instants that no longer fit
+	}
+	for 0 != len(p.fifo) {
+		fifo <- <-p.fifo
+	}
+
+	p.limit = limit
+	p.duration = period
+	p.fifo = fifo
+	return
+}
+
+//	The caller must hold the lock (or have the only reference to p).
+func (p *TThrottle) init(limit uint, period time.Duration) (err error) {
+	if err = validate(limit, period); nil == err {
+		p.limit = limit
+		p.duration = period
+		p.fifo = make(chan time.Time, limit)
+	}
+	return
+}
+
+/*	GetSleepDuration commits the next allowable call to the window and returns how long the caller
+	must sleep before making it.
+	If the throttle was never initialized, it returns 0 rather than bring the process down, so calls
+	go through unthrottled.
+
+	Deprecated: GetSleepDuration can't report that failure.  Use Wait(), or GetSleepDurationN(1), both of
+	which return ErrNotInitialized instead.
+*/
 func (p *TThrottle) GetSleepDuration() (dur time.Duration) {
-	if nil == p.fifo {	//	guard against pinheadedness
-		log.Fatalln("Programmer forgot to call Init() on the throttle object")
+	dur, _ = p.GetSleepDurationN(1)
+	return
+}
+
+/*	GetSleepDurationN is GetSleepDuration for a call costing n slots, all of which are committed to
+	the window at once.  It returns an error wrapping ErrExceedsLimit if n exceeds the limit, since such
+	a call could never be made.
+*/
+func (p *TThrottle) GetSleepDurationN(n uint) (dur time.Duration, err error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if err = p.checkN(n); nil != err {
+		return
 	}
+	now := p.now()	//	latch this instant
+
+	if instant, _ := p.reserve(now, n); instant.After(now) {
+		dur = instant.Sub(now)
+	}
+	p.acquired(n, dur)
+	return
+}
+
+/*	TryAcquire claims a slot only if one is available right now, and reports whether it did.
+	Unlike GetSleepDuration(), nothing is committed to the window when it returns false.
+*/
+func (p *TThrottle) TryAcquire() bool {
+	return p.TryAcquireN(1)
+}
+
+//	TryAcquireN is TryAcquire for a call costing n slots.
+func (p *TThrottle) TryAcquireN(n uint) (ok bool) {
 	p.Lock()
-	now := time.Now()	//	latch this instant
+	defer p.Unlock()
 
-	if p.limit == uint(len(p.fifo)) {
-		//	remove the oldest instant from p.fifo and compute the instant of the next allowable call
-		next := (<-p.fifo).Add(p.duration)
+	if nil != p.checkN(n) {
+		return
+	}
+	now := p.now()	//	latch this instant
 
-		if(next.After(now)) {
-			p.fifo <- next
-			dur = next.Sub(now)
-		} else {
-			p.fifo <- now
-		}
+	instant, xEvicted := p.reserve(now, n)
+	if ok = !instant.After(now); ok {
+		p.acquired(n, 0)
 	} else {
-		p.fifo <- now
+		p.unreserve(instant, n, xEvicted)
 	}
+	return
+}
+
+/*	Reserve claims the next available slot and returns a *Reservation describing when it may be used.
+	The caller may then sleep for Reservation.Delay(), or change its mind and call Reservation.Cancel()
+	to give the slot back.
+	If the throttle was never initialized, the returned Reservation's OK() is false.
+*/
+func (p *TThrottle) Reserve() *Reservation {
+	return p.ReserveN(1)
+}
+
+//	ReserveN is Reserve for a call costing n slots.  OK() is also false if n exceeds the limit.
+func (p *TThrottle) ReserveN(n uint) (pReservation *Reservation) {
+	pReservation, _ = p.reserveN(n)
+	return
+}
+
+func (p *TThrottle) reserveN(n uint) (pReservation *Reservation, err error) {
+	pReservation = &Reservation{locker: p, clock: p.getClock()}
+
+	p.Lock()
+	defer p.Unlock()
 
-	p.Unlock()
+	if err = p.checkN(n); nil != err {
+		return
+	}
+	now := p.now()	//	latch this instant
+	instant, xEvicted := p.reserve(now, n)
+	pReservation.grant(instant, func() {
+		p.unreserve(instant, n, xEvicted)
+		p.canceled(n)
+	})
+	p.acquired(n, max(0, instant.Sub(now)))
+	return
+}
+
+/*	Wait blocks until a slot is available or ctx is done, whichever comes first.
+	If ctx is done first, the slot that was reserved for this call is given back so that it may be
+	claimed by a subsequent caller, and ctx.Err() is returned.
+	If ctx carries a deadline that would expire before the slot becomes available, Wait gives the
+	slot back and returns context.DeadlineExceeded immediately rather than sleeping in vain.
+*/
+func (p *TThrottle) Wait(ctx context.Context) error {
+	return p.WaitN(ctx, 1)
+}
+
+//	WaitN is Wait for a call costing n slots.  It returns an error wrapping ErrExceedsLimit if n exceeds the limit.
+func (p *TThrottle) WaitN(ctx context.Context, n uint) (err error) {
+	if err = ctx.Err(); nil != err {
+		return
+	}
+
+	var pReservation *Reservation
+	if pReservation, err = p.reserveN(n); nil == err {
+		err = pReservation.wait(ctx)
+	}
+	return
+}
+
+func (p *TThrottle) getClock() (c Clock) {
+	if c = p.clock; nil == c {
+		c = tSystemClock{}
+	}
+	return
+}
+
+func (p *TThrottle) now() time.Time {
+	return p.getClock().Now()
+}
+
+/*	idle reports whether nothing has been reserved within the last period, meaning the window is empty
+	and the throttle could be discarded without losing any state.
+*/
+func (p *TThrottle) idle(now time.Time) bool {
+	p.Lock()
+	defer p.Unlock()
+	return !now.Before(p.latest.Add(p.duration))
+}
+
+//	The caller must hold the lock.
+func (p *TThrottle) checkN(n uint) (err error) {
+	if nil == p.fifo {	//	guard against pinheadedness
+		err = ErrNotInitialized
+	} else if n > p.limit {
+		err = fmt.Errorf(`%w: n = %d; limit = %d`, ErrExceedsLimit, n, p.limit)
+	}
+	return
+}
+
+/*	reserve pushes n copies of the instant of the next allowable call onto p.fifo and returns it.
+	If p.fifo was too full, the oldest instants had to be removed to make room, and they are returned
+	as xEvicted so that unreserve() can put them back.
+	The caller must hold the lock and must have already checked that n <= p.limit.
+*/
+func (p *TThrottle) reserve(now time.Time, n uint) (instant time.Time, xEvicted []time.Time) {
+	instant = now
+
+	//	remove the oldest instants from p.fifo; the last one removed determines the instant of the next allowable call
+	for p.limit < uint(len(p.fifo)) + n {
+		xEvicted = append(xEvicted, <-p.fifo)
+	}
+	if 0 != len(xEvicted) {
+		if next := xEvicted[len(xEvicted) - 1].Add(p.duration); next.After(now) {
+			instant = next
+		}
+	}
+
+	for i := uint(0); i < n; i++ {
+		p.fifo <- instant
+	}
+	p.latest = instant
+	return
+}
+
+/*	unreserve undoes a previous reserve(): it removes n copies of instant from p.fifo and restores
+	the instants reserve() evicted to make room at the head of the queue.
+	The caller must hold the lock.
+*/
+func (p *TThrottle) unreserve(instant time.Time, n uint, xEvicted []time.Time) {
+	//	drain p.fifo, since a channel doesn't allow removal from the middle or insertion at the head
+	xInstants := make([]time.Time, 0, len(xEvicted) + len(p.fifo))
+	xInstants = append(xInstants, xEvicted...)
+	for 0 != len(p.fifo) {
+		xInstants = append(xInstants, <-p.fifo)
+	}
+
+	//	remove the most recent n occurrences of instant
+	for i := len(xInstants) - 1; 0 <= i && 0 != n; i-- {
+		if xInstants[i].Equal(instant) {
+			xInstants = append(xInstants[:i], xInstants[i+1:]...)
+			n--
+		}
+	}
+
+	/*	xEvicted went back at the head, but if reservations made since are canceled out of order, some
+		of it may be newer than what remains; restore chronological order so the head is the oldest again.
+	*/
+	sortInstants(xInstants)
+
+	//	refill, dropping the oldest instants should there somehow be more than will fit
+	if overflow := len(xInstants) - int(p.limit); 0 < overflow {
+		xInstants = xInstants[overflow:]
+	}
+	for _, t := range xInstants {
+		p.fifo <- t
+	}
+}
+
+
+//\\//	functions
+
+//	validate checks the arguments common to all of this package's constructors.
+func validate(limit uint, period time.Duration) (err error) {
+	if 0 == limit {
+		err = ErrZeroLimit
+	} else if 0 >= period {
+		err = fmt.Errorf(`%w: %v`, ErrInvalidPeriod, period)
+	}
+	return
+}
+
+//	sortInstants sorts a sliding log into chronological order, keeping equal instants in place.
+func sortInstants(xInstants []time.Time) {
+	sort.SliceStable(xInstants, func(i, j int) bool {
+		return xInstants[i].Before(xInstants[j])
+	})
+}
+
+/*	New returns a throttle allowing at most limit calls within any period.
+	Unlike Init(), period may be any positive duration, including fractions of a second.
+*/
+func New(limit uint, period time.Duration, xOptions ...Option) (p *TThrottle, err error) {
+	options := applyOptions(xOptions)
+	p = &TThrottle{clock: options.clock, observer: options.observer}
+	if err = p.init(limit, period); nil != err {
+		p = nil
+	}
 	return
 }
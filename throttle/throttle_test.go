@@ -0,0 +1,263 @@
+package throttle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/imtlab/pkg/throttle"
+	"github.com/imtlab/pkg/throttle/throttletest"
+)
+
+//	kStart is a period-aligned instant, so that window boundaries fall on whole seconds.
+var kStart = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func newThrottle(t *testing.T, limit uint, period time.Duration) (*throttle.TThrottle, *throttletest.FakeClock) {
+	t.Helper()
+	clock := throttletest.NewFakeClock(kStart)
+	p, err := throttle.New(limit, period, throttle.WithClock(clock))
+	if nil != err {
+		t.Fatalf(`New() returned error: %v`, err)
+	}
+	return p, clock
+}
+
+func TestSlidingLog(t *testing.T) {
+	p, clock := newThrottle(t, 3, time.Second)
+
+	for i := 0; i < 3; i++ {
+		if dur := p.GetSleepDuration(); 0 != dur {
+			t.Fatalf(`call %d: slept %v; want 0`, i, dur)
+		}
+		clock.Advance(100 * time.Millisecond)
+	}
+	if p.TryAcquire() {
+		t.Fatal(`TryAcquire() succeeded with the window full`)
+	}
+
+	//	the oldest call was at 0ms and it is now 300ms, so the next slot opens at 1s
+	if dur := p.GetSleepDuration(); 700 * time.Millisecond != dur {
+		t.Fatalf(`slept %v; want 700ms`, dur)
+	}
+	//	and the one after that when the call at 100ms leaves the window
+	if dur := p.GetSleepDuration(); 800 * time.Millisecond != dur {
+		t.Fatalf(`slept %v; want 800ms`, dur)
+	}
+
+	clock.Advance(2 * time.Second)
+	if !p.TryAcquire() {
+		t.Fatal(`TryAcquire() failed once the window had passed`)
+	}
+}
+
+func TestSlidingLogN(t *testing.T) {
+	p, clock := newThrottle(t, 3, time.Second)
+
+	if dur, err := p.GetSleepDurationN(2); nil != err || 0 != dur {
+		t.Fatalf(`GetSleepDurationN(2) = %v, %v; want 0, nil`, dur, err)
+	}
+	clock.Advance(500 * time.Millisecond)
+	if p.TryAcquireN(2) {
+		t.Fatal(`TryAcquireN(2) succeeded with only one slot free`)
+	}
+	if !p.TryAcquireN(1) {
+		t.Fatal(`TryAcquireN(1) failed with one slot free`)
+	}
+	if _, err := p.GetSleepDurationN(4); !errors.Is(err, throttle.ErrExceedsLimit) {
+		t.Fatalf(`GetSleepDurationN(4) returned %v; want ErrExceedsLimit`, err)
+	}
+}
+
+func TestWait(t *testing.T) {
+	p, clock := newThrottle(t, 1, time.Second)
+	p.TryAcquire()
+
+	chErr := make(chan error)
+	go func() {
+		chErr <- p.Wait(context.Background())
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(999 * time.Millisecond)
+	select {
+	case err := <-chErr:
+		t.Fatalf(`Wait() returned %v before its slot opened`, err)
+	default:
+	}
+
+	clock.Advance(time.Millisecond)
+	if err := <-chErr; nil != err {
+		t.Fatalf(`Wait() returned %v`, err)
+	}
+}
+
+func TestWaitCancel(t *testing.T) {
+	p, clock := newThrottle(t, 1, time.Second)
+	p.TryAcquire()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chErr := make(chan error)
+	go func() {
+		chErr <- p.Wait(ctx)
+	}()
+
+	clock.BlockUntil(1)
+	cancel()
+	if err := <-chErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf(`Wait() returned %v; want context.Canceled`, err)
+	}
+	if 0 != clock.Waiters() {
+		t.Fatalf(`Waiters() = %d after Wait() gave up; want 0`, clock.Waiters())
+	}
+
+	//	the canceled slot was given back, so the next caller gets it rather than the one after
+	if dur := p.Reserve().Delay(); time.Second != dur {
+		t.Fatalf(`Reserve().Delay() = %v; want 1s`, dur)
+	}
+}
+
+func TestWaitDeadline(t *testing.T) {
+	//	context deadlines are wall-clock times, so start the fake clock at the real time
+	clock := throttletest.NewFakeClock(time.Now())
+	p, _ := throttle.New(1, time.Second, throttle.WithClock(clock))
+	p.TryAcquire()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	chErr := make(chan error)
+	go func() {
+		chErr <- p.Wait(ctx)
+	}()
+	clock.BlockUntil(1)	//	a deadline a minute off doesn't stop it waiting
+	clock.Advance(time.Second)
+	if err := <-chErr; nil != err {
+		t.Fatalf(`Wait() returned %v`, err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100 * time.Millisecond)
+	defer cancel()
+	if err := p.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf(`Wait() returned %v; want context.DeadlineExceeded at once`, err)
+	}
+	if 0 != clock.Waiters() {
+		t.Fatal(`Wait() went to sleep despite the deadline`)
+	}
+
+	//	the deadline is measured on the fake clock, now a second ahead of the real one, so a timeout of 1.5s
+	//	leaves only 500ms or so, too little for the next slot a second off
+	ctx, cancel = context.WithTimeout(context.Background(), 1500 * time.Millisecond)
+	defer cancel()
+	if err := p.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf(`Wait() returned %v; want context.DeadlineExceeded at once`, err)
+	}
+}
+
+func TestReserveCancel(t *testing.T) {
+	p, clock := newThrottle(t, 2, time.Second)
+	p.TryAcquireN(2)
+	clock.Advance(100 * time.Millisecond)
+
+	pReservation := p.Reserve()
+	if !pReservation.OK() || 900 * time.Millisecond != pReservation.Delay() {
+		t.Fatalf(`Reserve() = OK %v, Delay %v; want OK, 900ms`, pReservation.OK(), pReservation.Delay())
+	}
+	pReservation.Cancel()
+	pReservation.Cancel()	//	no effect the second time
+
+	if dur := p.Reserve().Delay(); 900 * time.Millisecond != dur {
+		t.Fatalf(`Reserve().Delay() after Cancel() = %v; want 900ms`, dur)
+	}
+	if dur := p.Reserve().Delay(); 900 * time.Millisecond != dur {
+		t.Fatalf(`second Reserve().Delay() = %v; want 900ms`, dur)
+	}
+	if dur := p.Reserve().Delay(); 1900 * time.Millisecond != dur {
+		t.Fatalf(`third Reserve().Delay() = %v; want 1.9s`, dur)
+	}
+}
+
+func TestCancelOutOfOrder(t *testing.T) {
+	p, clock := newThrottle(t, 2, time.Second)
+	p.TryAcquire()
+	clock.Advance(100 * time.Millisecond)
+	p.TryAcquire()
+
+	a, b := p.Reserve(), p.Reserve()
+	a.Cancel()
+	b.Cancel()
+
+	//	the log is back to [0ms, 100ms], so the next slots open at 1s and 1.1s
+	if dur := p.Reserve().Delay(); 900 * time.Millisecond != dur {
+		t.Fatalf(`Reserve().Delay() = %v; want 900ms`, dur)
+	}
+	if dur := p.Reserve().Delay(); time.Second != dur {
+		t.Fatalf(`second Reserve().Delay() = %v; want 1s`, dur)
+	}
+}
+
+func TestSetLimit(t *testing.T) {
+	p, clock := newThrottle(t, 3, time.Second)
+	for i := 0; i < 3; i++ {
+		p.TryAcquire()
+		clock.Advance(100 * time.Millisecond)
+	}
+
+	//	shrinking keeps the most recent calls, at 100ms and 200ms
+	if err := p.SetLimit(2, time.Second); nil != err {
+		t.Fatalf(`SetLimit() returned %v`, err)
+	}
+	if dur := p.Reserve().Delay(); 800 * time.Millisecond != dur {
+		t.Fatalf(`Reserve().Delay() after shrinking = %v; want 800ms`, dur)
+	}
+
+	//	growing makes room at once
+	if err := p.SetLimit(4, time.Second); nil != err {
+		t.Fatalf(`SetLimit() returned %v`, err)
+	}
+	if !p.TryAcquire() {
+		t.Fatal(`TryAcquire() failed after growing the limit`)
+	}
+
+	if err := p.SetLimit(0, time.Second); !errors.Is(err, throttle.ErrZeroLimit) {
+		t.Fatalf(`SetLimit(0) returned %v; want ErrZeroLimit`, err)
+	}
+	if err := p.SetLimit(1, 0); !errors.Is(err, throttle.ErrInvalidPeriod) {
+		t.Fatalf(`SetLimit(1, 0) returned %v; want ErrInvalidPeriod`, err)
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var p throttle.TThrottle
+
+	if dur := p.GetSleepDuration(); 0 != dur {
+		t.Fatalf(`GetSleepDuration() = %v; want 0`, dur)
+	}
+	if _, err := p.GetSleepDurationN(1); !errors.Is(err, throttle.ErrNotInitialized) {
+		t.Fatalf(`GetSleepDurationN() returned %v; want ErrNotInitialized`, err)
+	}
+	if err := p.Wait(context.Background()); !errors.Is(err, throttle.ErrNotInitialized) {
+		t.Fatalf(`Wait() returned %v; want ErrNotInitialized`, err)
+	}
+	if p.TryAcquire() || p.Reserve().OK() {
+		t.Fatal(`an uninitialized throttle granted a slot`)
+	}
+
+	if err := p.Init(0, 1); !errors.Is(err, throttle.ErrZeroLimit) {
+		t.Fatalf(`Init(0, 1) returned %v; want ErrZeroLimit`, err)
+	}
+	if err := p.Init(1, 1); nil != err {
+		t.Fatalf(`Init() returned %v`, err)
+	}
+	if !p.TryAcquire() {
+		t.Fatal(`TryAcquire() failed after Init()`)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := throttle.New(0, time.Second); !errors.Is(err, throttle.ErrZeroLimit) {
+		t.Fatalf(`New(0, 1s) returned %v; want ErrZeroLimit`, err)
+	}
+	if _, err := throttle.New(1, -time.Second); !errors.Is(err, throttle.ErrInvalidPeriod) {
+		t.Fatalf(`New(1, -1s) returned %v; want ErrInvalidPeriod`, err)
+	}
+}
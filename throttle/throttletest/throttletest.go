@@ -0,0 +1,148 @@
+/*	Package throttletest provides a fake throttle.Clock so that code using package throttle can be
+	tested against virtual time instead of real sleeps.
+*/
+package throttletest
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+//\\//	type definitions (and attached methods)
+
+type tWaiter struct {
+	until	time.Time
+	c		chan time.Time
+}
+
+/*	A FakeClock implements throttle.Clock and throttle.TimerClock.  Time stands still until Advance() or
+	Set() is called, at which point any channels returned by After() or Timer() that have come due receive
+	the new time.
+*/
+type FakeClock struct {
+	sync.Mutex
+	now			time.Time
+	xWaiters	[]tWaiter
+	chChanged	chan struct{}	//	closed and replaced whenever xWaiters changes, to wake BlockUntil()
+}
+
+func (p *FakeClock) Now() time.Time {
+	p.Lock()
+	defer p.Unlock()
+	return p.now
+}
+
+/*	After's channels stay pending until they fire, even if nobody is left to receive from them.
+	Use Timer() for one that may be abandoned.
+*/
+func (p *FakeClock) After(d time.Duration) <-chan time.Time {
+	p.Lock()
+	defer p.Unlock()
+	return p.add(d)
+}
+
+//	Timer is like After(), except that calling stop removes the channel from those pending.
+func (p *FakeClock) Timer(d time.Duration) (<-chan time.Time, func()) {
+	p.Lock()
+	defer p.Unlock()
+
+	c := p.add(d)
+	return c, func() {
+		p.Lock()
+		defer p.Unlock()
+		for i, waiter := range p.xWaiters {
+			if c == waiter.c {
+				p.xWaiters = append(p.xWaiters[:i], p.xWaiters[i + 1:]...)
+				p.changed()
+				return
+			}
+		}
+	}
+}
+
+//	Advance moves the clock forward by d, firing any After() channels that come due.
+func (p *FakeClock) Advance(d time.Duration) {
+	p.Lock()
+	defer p.Unlock()
+	p.set(p.now.Add(d))
+}
+
+//	Set moves the clock to t, firing any After() channels that come due.  Moving it backwards fires nothing.
+func (p *FakeClock) Set(t time.Time) {
+	p.Lock()
+	defer p.Unlock()
+	p.set(t)
+}
+
+//	Waiters returns the number of After() and Timer() channels that have neither fired nor been stopped.
+func (p *FakeClock) Waiters() int {
+	p.Lock()
+	defer p.Unlock()
+	return len(p.xWaiters)
+}
+
+/*	BlockUntil blocks until at least n After() or Timer() channels are pending.
+	Use it to be sure a goroutine has gone to sleep in throttle.TThrottle.Wait() before calling Advance().
+*/
+func (p *FakeClock) BlockUntil(n int) {
+	for {
+		p.Lock()
+		if n <= len(p.xWaiters) {
+			p.Unlock()
+			return
+		}
+		if nil == p.chChanged {	//	zero-value FakeClock
+			p.chChanged = make(chan struct{})
+		}
+		chChanged := p.chChanged
+		p.Unlock()
+		<-chChanged
+	}
+}
+
+//	add returns a channel that receives once d has passed.  The caller must hold the lock.
+func (p *FakeClock) add(d time.Duration) chan time.Time {
+	c := make(chan time.Time, 1)	//	buffered so that Advance() never blocks
+	if 0 >= d {
+		c <- p.now
+	} else {
+		p.xWaiters = append(p.xWaiters, tWaiter{until: p.now.Add(d), c: c})
+		p.changed()
+	}
+	return c
+}
+
+//	The caller must hold the lock.
+func (p *FakeClock) set(t time.Time) {
+	p.now = t
+
+	//	fire in chronological order
+	sort.SliceStable(p.xWaiters, func(i, j int) bool {
+		return p.xWaiters[i].until.Before(p.xWaiters[j].until)
+	})
+
+	var i int
+	for i = 0; i < len(p.xWaiters) && !p.xWaiters[i].until.After(t); i++ {
+		p.xWaiters[i].c <- t
+	}
+	if 0 != i {
+		p.xWaiters = p.xWaiters[i:]
+		p.changed()
+	}
+}
+
+//	The caller must hold the lock.
+func (p *FakeClock) changed() {
+	if nil != p.chChanged {
+		close(p.chChanged)
+	}
+	p.chChanged = make(chan struct{})
+}
+
+//\\//	functions
+
+//	NewFakeClock returns a FakeClock reading start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start, chChanged: make(chan struct{})}
+}
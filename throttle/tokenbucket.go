@@ -0,0 +1,116 @@
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var _ Limiter = (*TTokenBucket)(nil)
+
+/*	A TTokenBucket refills at limit tokens per period, up to burst tokens, and each call spends
+	one token (or n).  Its memory footprint is constant regardless of limit.
+	Create one with NewTokenBucket().
+*/
+type TTokenBucket struct {
+	sync.Mutex
+	burst		uint
+	perToken	time.Duration	//	time to refill one token
+	clock		Clock
+	tokens		float64			//	may go negative: that's the debt owed by outstanding reservations
+	last		time.Time		//	the instant tokens was last brought up to date
+}
+
+func (p *TTokenBucket) Wait(ctx context.Context) error {
+	return waitN(ctx, p, 1)
+}
+
+func (p *TTokenBucket) WaitN(ctx context.Context, n uint) error {
+	return waitN(ctx, p, n)
+}
+
+func (p *TTokenBucket) TryAcquire() bool {
+	return tryAcquireN(p, 1)
+}
+
+func (p *TTokenBucket) TryAcquireN(n uint) bool {
+	return tryAcquireN(p, n)
+}
+
+func (p *TTokenBucket) Reserve() *Reservation {
+	return reserveN(p, 1)
+}
+
+func (p *TTokenBucket) ReserveN(n uint) *Reservation {
+	return reserveN(p, n)
+}
+
+func (p *TTokenBucket) getClock() Clock {
+	return p.clock
+}
+
+func (p *TTokenBucket) checkN(n uint) (err error) {
+	if nil == p.clock {	//	guard against pinheadedness
+		err = ErrNotInitialized
+	} else if n > p.burst {
+		err = fmt.Errorf(`%w: n = %d; burst = %d`, ErrExceedsLimit, n, p.burst)
+	}
+	return
+}
+
+//	refill brings p.tokens up to date as of now.  The caller must hold the lock.
+func (p *TTokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(p.last); 0 < elapsed {
+		p.tokens += float64(elapsed) / float64(p.perToken)
+		if burst := float64(p.burst); p.tokens > burst {
+			p.tokens = burst
+		}
+		p.last = now
+	}
+}
+
+func (p *TTokenBucket) reserve(now time.Time, n uint) (instant time.Time, undo func()) {
+	p.refill(now)
+	p.tokens -= float64(n)
+
+	instant = now
+	if 0 > p.tokens {
+		//	wait for the debt to be repaid
+		instant = now.Add(time.Duration(-p.tokens * float64(p.perToken)))
+	}
+
+	undo = func() {
+		p.refill(p.clock.Now())
+		p.tokens += float64(n)
+		if burst := float64(p.burst); p.tokens > burst {
+			p.tokens = burst
+		}
+	}
+	return
+}
+
+/*	NewTokenBucket returns a token bucket that refills at limit tokens per period and holds at most
+	burst tokens, starting full.  A burst of zero means limit.
+*/
+func NewTokenBucket(limit uint, period time.Duration, burst uint, xOptions ...Option) (p *TTokenBucket, err error) {
+	if err = validate(limit, period); nil != err {
+		return
+	}
+	if 0 == burst {
+		burst = limit
+	}
+
+	options := applyOptions(xOptions)
+	p = &TTokenBucket{
+		burst:		burst,
+		perToken:	period / time.Duration(limit),
+		clock:		options.clock,
+		tokens:		float64(burst),
+		last:		options.clock.Now(),
+	}
+	if 0 == p.perToken {
+		p, err = nil, fmt.Errorf(`%w: %v is too short for a limit of %d`, ErrInvalidPeriod, period, limit)
+	}
+	return
+}
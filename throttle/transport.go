@@ -0,0 +1,103 @@
+package throttle
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+/*	Transport is an http.RoundTripper that waits for a slot before passing each request on to Base,
+	so any *http.Client can be limited in one line:
+		client := &http.Client{Transport: &throttle.Transport{Limiter: pThrottle}}
+	Waiting respects the request's context.  Limiter applies to every request, and Hosts, if set,
+	additionally applies a separate limit per request host; either may be nil.
+	If Limiter is a *TAdaptive, it is fed back each response: 429 Too Many Requests and 503 Service
+	Unavailable penalize it (honouring any Retry-After header), anything else counts as a success.
+*/
+type Transport struct {
+	Base	http.RoundTripper	//	nil means http.DefaultTransport
+	Limiter	Limiter
+	Hosts	*Group
+}
+
+func (p *Transport) RoundTrip(pRequest *http.Request) (pResponse *http.Response, err error) {
+	var xLimiters []Limiter
+	if nil != p.Limiter {
+		xLimiters = append(xLimiters, p.Limiter)
+	}
+	if nil != p.Hosts {
+		xLimiters = append(xLimiters, p.Hosts.Get(pRequest.URL.Host))
+	}
+
+	if err = waitAll(pRequest.Context(), xLimiters); nil != err {
+		//	a RoundTripper must always close the body, even on error
+		if nil != pRequest.Body {
+			pRequest.Body.Close()
+		}
+		return
+	}
+
+	base := p.Base
+	if nil == base {
+		base = http.DefaultTransport
+	}
+	if pResponse, err = base.RoundTrip(pRequest); nil == err {
+		if pAdaptive, ok := p.Limiter.(*TAdaptive); ok {
+			switch pResponse.StatusCode {
+			case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+				pAdaptive.Penalize(parseRetryAfter(pResponse.Header.Get(`Retry-After`), pAdaptive.clock.Now()))
+			default:
+				pAdaptive.Succeed()
+			}
+		}
+	}
+	return
+}
+
+/*	waitAll waits for a slot from each of xLimiters.  The slots are all reserved before waiting for any,
+	so that if ctx is done or a Limiter refuses, the slots reserved from the others are given back
+	rather than spent on a request that is never sent.
+*/
+func waitAll(ctx context.Context, xLimiters []Limiter) (err error) {
+	if err = ctx.Err(); nil != err {
+		return
+	}
+
+	xReservations := make([]*Reservation, 0, len(xLimiters))
+	for _, limiter := range xLimiters {
+		if pReservation := limiter.Reserve(); pReservation.OK() {
+			xReservations = append(xReservations, pReservation)
+		} else if err = limiter.Wait(ctx); nil != err {	//	Reserve() doesn't say why; Wait() will
+			break
+		}
+	}
+
+	//	the instants are absolute, so waiting for each in turn takes only as long as the latest
+	for i := 0; nil == err && i < len(xReservations); i++ {
+		err = xReservations[i].wait(ctx)
+	}
+
+	if nil != err {
+		for _, pReservation := range xReservations {
+			pReservation.release()
+		}
+	}
+	return
+}
+
+//	parseRetryAfter interprets a Retry-After header, which holds either a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (dur time.Duration) {
+	if 0 == len(value) {
+		return
+	}
+	if seconds, err := strconv.Atoi(value); nil == err {
+		dur = time.Duration(seconds) * time.Second
+	} else if t, err := http.ParseTime(value); nil == err {
+		dur = t.Sub(now)
+	}
+	if 0 > dur {
+		dur = 0
+	}
+	return
+}
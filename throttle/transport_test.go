@@ -0,0 +1,110 @@
+package throttle_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/imtlab/pkg/throttle"
+	"github.com/imtlab/pkg/throttle/throttletest"
+)
+
+type tRoundTripper func(*http.Request) (*http.Response, error)
+
+func (f tRoundTripper) RoundTrip(pRequest *http.Request) (*http.Response, error) {
+	return f(pRequest)
+}
+
+func TestTransport(t *testing.T) {
+	var count int
+	base := tRoundTripper(func(*http.Request) (*http.Response, error) {
+		count++
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	shared, _ := throttle.New(1, time.Hour)
+	p := &throttle.Transport{Base: base, Limiter: shared}
+	pRequest, _ := http.NewRequest(http.MethodGet, `http://example.com/`, nil)
+
+	if _, err := p.RoundTrip(pRequest); nil != err || 1 != count {
+		t.Fatalf(`RoundTrip() returned %v after %d calls`, err, count)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10 * time.Millisecond)
+	defer cancel()
+	if _, err := p.RoundTrip(pRequest.WithContext(ctx)); context.DeadlineExceeded != err || 1 != count {
+		t.Fatalf(`RoundTrip() over the limit returned %v after %d calls; want DeadlineExceeded and 1`, err, count)
+	}
+}
+
+func TestTransportFeedback(t *testing.T) {
+	clock := throttletest.NewFakeClock(kStart)
+	pAdaptive, _ := throttle.NewAdaptive(2, time.Second, throttle.WithClock(clock))
+
+	var pResponse *http.Response
+	base := tRoundTripper(func(*http.Request) (*http.Response, error) {
+		return pResponse, nil
+	})
+	p := &throttle.Transport{Base: base, Limiter: pAdaptive}
+	pRequest, _ := http.NewRequest(http.MethodGet, `http://example.com/`, nil)
+
+	//	roundTrip sends a request, answered with status and Retry-After, and returns how long the next must wait
+	roundTrip := func(status int, retryAfter string) time.Duration {
+		t.Helper()
+		clock.Advance(time.Minute)	//	out of the way of the previous round trip
+		pResponse = &http.Response{StatusCode: status, Header: http.Header{}, Body: http.NoBody}
+		if 0 != len(retryAfter) {
+			pResponse.Header.Set(`Retry-After`, retryAfter)
+		}
+		if _, err := p.RoundTrip(pRequest); nil != err {
+			t.Fatalf(`RoundTrip() returned %v`, err)
+		}
+		pReservation := pAdaptive.ReserveN(pAdaptive.Limit())
+		defer pReservation.Cancel()
+		return pReservation.Delay()
+	}
+
+	if dur := roundTrip(http.StatusTooManyRequests, `2`); 2 * time.Second != dur || 1 != pAdaptive.Limit() {
+		t.Fatalf(`after 429 with Retry-After in seconds: delay %v, Limit() %d; want 2s, 1`, dur, pAdaptive.Limit())
+	}
+	retryAfter := clock.Now().Add(time.Minute + 3 * time.Second).Format(http.TimeFormat)
+	if dur := roundTrip(http.StatusServiceUnavailable, retryAfter); 3 * time.Second != dur {
+		t.Fatalf(`after 503 with Retry-After as a date: delay %v; want 3s`, dur)
+	}
+	//	without a Retry-After to honour, the next slot opens when the request leaves the window
+	if dur := roundTrip(http.StatusTooManyRequests, `soon`); time.Second != dur {
+		t.Fatalf(`after 429 with an unreadable Retry-After: delay %v; want 1s`, dur)
+	}
+
+	//	successes win the limit back
+	for i := 0; i < 2; i++ {
+		roundTrip(http.StatusOK, ``)
+	}
+	if 2 != pAdaptive.Limit() {
+		t.Fatalf(`Limit() after 2 successes = %d; want 2`, pAdaptive.Limit())
+	}
+}
+
+func TestTransportGivesBackSharedSlot(t *testing.T) {
+	base := tRoundTripper(func(*http.Request) (*http.Response, error) {
+		t.Fatal(`the request was sent`)
+		return nil, nil
+	})
+
+	shared, _ := throttle.New(1, time.Hour)
+	hosts, _ := throttle.NewGroup(1, time.Hour, time.Hour)
+	hosts.TryAcquire(`example.com`)
+	p := &throttle.Transport{Base: base, Limiter: shared, Hosts: hosts}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10 * time.Millisecond)
+	defer cancel()
+	pRequest, _ := http.NewRequestWithContext(ctx, http.MethodGet, `http://example.com/`, nil)
+	if _, err := p.RoundTrip(pRequest); context.DeadlineExceeded != err {
+		t.Fatalf(`RoundTrip() returned %v; want DeadlineExceeded`, err)
+	}
+
+	if !shared.TryAcquire() {
+		t.Fatal(`the shared slot was spent although the request was never sent`)
+	}
+}
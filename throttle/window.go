@@ -0,0 +1,214 @@
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+var (
+	_ Limiter = (*TFixedWindow)(nil)
+	_ Limiter = (*TSlidingWindow)(nil)
+)
+
+/*	tWindow holds what TFixedWindow and TSlidingWindow have in common: a count of the slots
+	committed to the current window, which is aligned to a multiple of period.
+	Slots are only ever committed at or after the most recent one, so the current window is the
+	one containing the latest reservation, which may lie in the future.
+*/
+type tWindow struct {
+	sync.Mutex
+	limit	uint
+	period	time.Duration
+	clock	Clock
+	start	time.Time	//	the start of the current window
+	count	uint		//	slots committed to the current window
+}
+
+func (p *tWindow) getClock() Clock {
+	return p.clock
+}
+
+func (p *tWindow) checkN(n uint) (err error) {
+	if nil == p.clock {	//	guard against pinheadedness
+		err = ErrNotInitialized
+	} else if n > p.limit {
+		err = fmt.Errorf(`%w: n = %d; limit = %d`, ErrExceedsLimit, n, p.limit)
+	}
+	return
+}
+
+func (p *tWindow) init(limit uint, period time.Duration, xOptions []Option) (err error) {
+	if err = validate(limit, period); nil == err {
+		p.limit = limit
+		p.period = period
+		p.clock = applyOptions(xOptions).clock
+	}
+	return
+}
+
+/*	A TFixedWindow allows at most limit calls within each period-aligned window (e.g. each clock hour).
+	It uses constant memory, at the cost of allowing up to twice limit calls across a window boundary.
+	Create one with NewFixedWindow().
+*/
+type TFixedWindow struct {
+	tWindow
+}
+
+func (p *TFixedWindow) Wait(ctx context.Context) error {
+	return waitN(ctx, p, 1)
+}
+
+func (p *TFixedWindow) WaitN(ctx context.Context, n uint) error {
+	return waitN(ctx, p, n)
+}
+
+func (p *TFixedWindow) TryAcquire() bool {
+	return tryAcquireN(p, 1)
+}
+
+func (p *TFixedWindow) TryAcquireN(n uint) bool {
+	return tryAcquireN(p, n)
+}
+
+func (p *TFixedWindow) Reserve() *Reservation {
+	return reserveN(p, 1)
+}
+
+func (p *TFixedWindow) ReserveN(n uint) *Reservation {
+	return reserveN(p, n)
+}
+
+func (p *TFixedWindow) reserve(now time.Time, n uint) (instant time.Time, undo func()) {
+	if start := now.Truncate(p.period); start.After(p.start) {
+		p.start, p.count = start, 0
+	}
+	if p.limit < p.count + n {
+		//	the current window is full, so move on to the next
+		p.start, p.count = p.start.Add(p.period), 0
+	}
+	p.count += n
+
+	instant = now
+	if p.start.After(now) {
+		instant = p.start
+	}
+
+	start := p.start
+	undo = func() {
+		if p.start.Equal(start) {
+			p.count -= n
+		}
+	}
+	return
+}
+
+/*	A TSlidingWindow approximates a sliding window by weighting the previous window's count by how
+	much of it still overlaps the sliding period.  It uses constant memory and, unlike TFixedWindow,
+	smooths out bursts at window boundaries.
+	Create one with NewSlidingWindow().
+*/
+type TSlidingWindow struct {
+	tWindow
+	previous	uint		//	slots committed to the window before the current one
+	latest		time.Time	//	the instant of the most recent reservation
+}
+
+func (p *TSlidingWindow) Wait(ctx context.Context) error {
+	return waitN(ctx, p, 1)
+}
+
+func (p *TSlidingWindow) WaitN(ctx context.Context, n uint) error {
+	return waitN(ctx, p, n)
+}
+
+func (p *TSlidingWindow) TryAcquire() bool {
+	return tryAcquireN(p, 1)
+}
+
+func (p *TSlidingWindow) TryAcquireN(n uint) bool {
+	return tryAcquireN(p, n)
+}
+
+func (p *TSlidingWindow) Reserve() *Reservation {
+	return reserveN(p, 1)
+}
+
+func (p *TSlidingWindow) ReserveN(n uint) *Reservation {
+	return reserveN(p, n)
+}
+
+//	roll advances the current window to the one containing t.  The caller must hold the lock.
+func (p *TSlidingWindow) roll(t time.Time) {
+	if start := t.Truncate(p.period); start.After(p.start) {
+		if start.Equal(p.start.Add(p.period)) {
+			p.previous = p.count
+		} else {
+			p.previous = 0	//	more than a whole window has gone by
+		}
+		p.start, p.count = start, 0
+	}
+}
+
+func (p *TSlidingWindow) reserve(now time.Time, n uint) (instant time.Time, undo func()) {
+	instant = now
+	if p.latest.After(instant) {
+		instant = p.latest
+	}
+
+	for {
+		p.roll(instant)
+
+		//	room left in the current window once the previous window's weighted count is accounted for
+		room := float64(p.limit) - float64(p.count) - float64(n)
+		if 0 > room {
+			instant = p.start.Add(p.period)
+			continue
+		}
+		if 0 == p.previous {
+			break
+		}
+
+		//	the fraction of the current window that must elapse for previous*(1 - fraction) to fit in room
+		fraction := 1 - room / float64(p.previous)
+		if elapsed := instant.Sub(p.start); float64(elapsed) >= fraction * float64(p.period) {
+			break
+		}
+		instant = p.start.Add(time.Duration(math.Ceil(fraction * float64(p.period))))
+	}
+
+	p.count += n
+	p.latest = instant
+
+	start := p.start
+	undo = func() {
+		if p.start.Equal(start) {
+			p.count -= n
+		} else if p.start.Equal(start.Add(p.period)) && n <= p.previous {
+			p.previous -= n
+		}
+	}
+	return
+}
+
+//\\//	functions
+
+//	NewFixedWindow returns a limiter allowing at most limit calls within each period-aligned window.
+func NewFixedWindow(limit uint, period time.Duration, xOptions ...Option) (p *TFixedWindow, err error) {
+	p = new(TFixedWindow)
+	if err = p.init(limit, period, xOptions); nil != err {
+		p = nil
+	}
+	return
+}
+
+//	NewSlidingWindow returns a limiter allowing approximately limit calls within any period.
+func NewSlidingWindow(limit uint, period time.Duration, xOptions ...Option) (p *TSlidingWindow, err error) {
+	p = new(TSlidingWindow)
+	if err = p.init(limit, period, xOptions); nil != err {
+		p = nil
+	}
+	return
+}
@@ -8,3 +8,6 @@ require (
 )
 
 require github.com/imtlab/pkg/loggers v0.0.0-20240623000418-ea5c0eebbcfe
+
+// redaction is newer than any published loggers, so build against the copy in this repository
+replace github.com/imtlab/pkg/loggers => ../loggers
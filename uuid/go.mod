@@ -2,4 +2,7 @@ module github.com/imtlab/pkg/uuid
 
 go 1.26.0
 
-require github.com/imtlab/pkg/loggers v0.0.0-20250203130836-d0d5cd572435
+require github.com/imtlab/pkg/loggers v0.0.0-20240623000418-ea5c0eebbcfe
+
+// Diagnostic() is newer than any published loggers, so build against the copy alongside this module
+replace github.com/imtlab/pkg/loggers => ../loggers
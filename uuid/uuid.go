@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"github.com/imtlab/pkg/loggers"
 )
 
@@ -17,25 +18,23 @@ const (
 */
 
 var (
-	//	set in init()
-	pRegexp	*regexp.Regexp
+	//	compiled on first use rather than in init(), so that importing this package costs nothing
+	getRegexp	= sync.OnceValue(func() *regexp.Regexp {
+		loggers.Diagnostic(`Compiling uuid pattern`)
+
+		//	sample "54510a02-6855-11ee-8457-46f5a3bf3389"
+		return regexp.MustCompile(`^[\da-f]{8}-([\da-f]{4}-){3}[\da-f]{12}$`)
+	})
 )
 
 //\\//	type definitions (and attached methods)
 
 //\\//	functions
 
-func init() {
-	loggers.Info.Println(`Executing uuid.init()`)
-
-	//	sample "54510a02-6855-11ee-8457-46f5a3bf3389"
-	pRegexp = regexp.MustCompile(`^[\da-f]{8}-([\da-f]{4}-){3}[\da-f]{12}$`)
-}
-
 func Validate(in string) (out string, err error) {
 	if out = strings.TrimSpace(in); 0 == len(out) {
 		err = errors.New(`No value`)
-	} else if !pRegexp.MatchString(out) {
+	} else if !getRegexp().MatchString(out) {
 		err = fmt.Errorf(`"%s" does not match UUID pattern`, out)
 	}
 